@@ -1,17 +1,27 @@
 package airports
 
-import "itinerary-prettifier/types"
+import (
+	"itinerary-prettifier/types"
+	"time"
+
+	// Embed the zone database so airport-local times work without system tzdata
+	_ "time/tzdata"
+)
 
 // Repository provides airport data access
 type Repository interface {
 	FindByCode(code string) (*types.Airport, bool)
+	FindPosition(code string) (types.Position, bool)
 	GetAll() map[string]types.Airport
 }
 
 // Service provides airport-related business logic
 type Service interface {
+	GetAirport(code string) (types.Airport, bool)
 	GetAirportName(code string) string
 	GetCityName(code string) string
+	GetPosition(code string) (types.Position, bool)
+	GetTimeZone(code string) (*time.Location, bool)
 }
 
 type AirportService struct {
@@ -22,6 +32,15 @@ func NewAirportService(repo Repository) *AirportService {
 	return &AirportService{repo: repo}
 }
 
+// GetAirport returns a copy of the full record for code
+func (s *AirportService) GetAirport(code string) (types.Airport, bool) {
+	airport, exists := s.repo.FindByCode(code)
+	if !exists {
+		return types.Airport{}, false
+	}
+	return *airport, true
+}
+
 func (s *AirportService) GetAirportName(code string) string {
 	airport, exists := s.repo.FindByCode(code)
 	if !exists {
@@ -37,3 +56,79 @@ func (s *AirportService) GetCityName(code string) string {
 	}
 	return airport.Municipality
 }
+
+func (s *AirportService) GetPosition(code string) (types.Position, bool) {
+	return s.repo.FindPosition(code)
+}
+
+// GetTimeZone resolves the airport's IANA zone; it reports false when the
+// airport is unknown or has no usable zone
+func (s *AirportService) GetTimeZone(code string) (*time.Location, bool) {
+	airport, exists := s.repo.FindByCode(code)
+	if !exists || airport.TimeZone == "" {
+		return nil, false
+	}
+	location, err := time.LoadLocation(airport.TimeZone)
+	if err != nil {
+		return nil, false
+	}
+	return location, true
+}
+
+// SourceRepository is a Repository that remembers which lookup file each code came from
+type SourceRepository interface {
+	Repository
+	SourceOf(code string) (string, bool)
+}
+
+// RecordingService wraps a Service and remembers every code it resolved
+type RecordingService struct {
+	Service
+	repo     Repository
+	resolved []string
+	seen     map[string]bool
+}
+
+func NewRecordingService(service Service, repo Repository) *RecordingService {
+	return &RecordingService{Service: service, repo: repo, seen: make(map[string]bool)}
+}
+
+func (s *RecordingService) GetAirport(code string) (types.Airport, bool) {
+	s.record(code)
+	return s.Service.GetAirport(code)
+}
+
+func (s *RecordingService) GetAirportName(code string) string {
+	s.record(code)
+	return s.Service.GetAirportName(code)
+}
+
+func (s *RecordingService) GetCityName(code string) string {
+	s.record(code)
+	return s.Service.GetCityName(code)
+}
+
+func (s *RecordingService) GetPosition(code string) (types.Position, bool) {
+	s.record(code)
+	return s.Service.GetPosition(code)
+}
+
+func (s *RecordingService) GetTimeZone(code string) (*time.Location, bool) {
+	s.record(code)
+	return s.Service.GetTimeZone(code)
+}
+
+func (s *RecordingService) record(code string) {
+	if s.seen[code] {
+		return
+	}
+	if _, exists := s.repo.FindByCode(code); exists {
+		s.seen[code] = true
+		s.resolved = append(s.resolved, code)
+	}
+}
+
+// Resolved returns the resolved codes in the order they were first seen
+func (s *RecordingService) Resolved() []string {
+	return s.resolved
+}
@@ -0,0 +1,65 @@
+package airports
+
+import (
+	"fmt"
+	"itinerary-prettifier/types"
+)
+
+// DuplicatePolicy decides which row keeps a code that appears more than once
+type DuplicatePolicy string
+
+const (
+	FirstWins       DuplicatePolicy = "first"
+	LastWins        DuplicatePolicy = "last"
+	FailOnDuplicate DuplicatePolicy = "error"
+)
+
+// ParseDuplicatePolicy converts a command line value into a DuplicatePolicy
+func ParseDuplicatePolicy(value string) (DuplicatePolicy, error) {
+	switch policy := DuplicatePolicy(value); policy {
+	case FirstWins, LastWins, FailOnDuplicate:
+		return policy, nil
+	case "":
+		return LastWins, nil
+	}
+	return "", fmt.Errorf("unknown duplicate policy: %s", value)
+}
+
+// ConflictKind tells a plain duplicate apart from one that disagrees on country
+type ConflictKind string
+
+const (
+	DuplicateCode   ConflictKind = "duplicate"
+	CountryMismatch ConflictKind = "country mismatch"
+)
+
+// Row is one airport record together with where it was read from
+type Row struct {
+	File    string
+	Line    int
+	Airport types.Airport
+	Raw     map[string]string // required, coordinate and time zone columns exactly as written in the file
+	Err     error             // why the record could not be read; Airport is empty when set
+}
+
+// Conflict describes two rows that claim the same lookup code
+type Conflict struct {
+	Code   string
+	Kind   ConflictKind
+	First  Row
+	Second Row
+}
+
+func newConflict(code string, first, second Row) Conflict {
+	kind := DuplicateCode
+	if first.Airport.ISOCountry != second.Airport.ISOCountry {
+		kind = CountryMismatch
+	}
+	return Conflict{Code: code, Kind: kind, First: first, Second: second}
+}
+
+func (c Conflict) Error() string {
+	return fmt.Sprintf("%s %s: %s:%d %q (%s) and %s:%d %q (%s)", c.Kind, c.Code,
+		c.First.File, c.First.Line, c.First.Airport.Name, c.First.Airport.ISOCountry,
+		c.Second.File, c.Second.Line, c.Second.Airport.Name, c.Second.Airport.ISOCountry)
+}
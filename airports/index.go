@@ -0,0 +1,301 @@
+package airports
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"itinerary-prettifier/types"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+)
+
+// indexMagic identifies an airport index file and its format version
+const indexMagic = "AIRIDX06"
+
+// IndexPath returns where the binary index for a lookup file is kept
+func IndexPath(lookupPath string) string {
+	return lookupPath + ".idx"
+}
+
+// Indexer precompiles lookup files into binary indexes
+type Indexer interface {
+	Build(lookupPath string) (string, error)
+}
+
+type BinaryIndexer struct {
+	parser Parser
+}
+
+func NewBinaryIndexer(parser Parser) *BinaryIndexer {
+	return &BinaryIndexer{parser: parser}
+}
+
+// Build parses the lookup file and writes its index next to it. The index
+// records the CSV's SHA-256 so a later edit to the CSV invalidates it.
+func (b *BinaryIndexer) Build(lookupPath string) (string, error) {
+	sum, err := hashFile(lookupPath)
+	if err != nil {
+		return "", fmt.Errorf("airport lookup not found: %w", err)
+	}
+
+	file, err := os.Open(lookupPath)
+	if err != nil {
+		return "", fmt.Errorf("airport lookup not found: %w", err)
+	}
+	defer file.Close()
+
+	table, err := b.parser.ParseTable(file)
+	if err != nil {
+		return "", fmt.Errorf("airport lookup malformed: %w", err)
+	}
+
+	indexPath := IndexPath(lookupPath)
+	data := encodeIndex(sum, b.parser.DuplicatePolicy(), table)
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write index: %w", err)
+	}
+	return indexPath, nil
+}
+
+// readIndex returns the table stored in the index for lookupPath. It reports
+// false when there is no index or it was built from different CSV contents or
+// under a different duplicate policy.
+func readIndex(lookupPath string, policy DuplicatePolicy) (*Table, bool) {
+	data, err := os.ReadFile(IndexPath(lookupPath))
+	if err != nil {
+		return nil, false
+	}
+	sum, err := hashFile(lookupPath)
+	if err != nil {
+		return nil, false
+	}
+
+	indexSum, indexPolicy, table, err := decodeIndex(data)
+	if err != nil || indexSum != sum || indexPolicy != policy {
+		return nil, false
+	}
+	return table, true
+}
+
+func hashFile(path string) ([32]byte, error) {
+	var sum [32]byte
+	file, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return sum, err
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+// airportFields lists the stored string fields of an airport in index order
+func airportFields(airport *types.Airport) []*string {
+	return []*string{
+		&airport.Name,
+		&airport.ISOCountry,
+		&airport.Municipality,
+		&airport.ICAO,
+		&airport.IATA,
+		&airport.Coordinates,
+		&airport.TimeZone,
+	}
+}
+
+// encodeIndex lays out the magic, CSV hash and policy, then every airport
+// once with its extra columns, then every plain lookup key with the position
+// of its airport, then the code collisions found while parsing.
+func encodeIndex(sum [32]byte, policy DuplicatePolicy, table *Table) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(indexMagic)
+	buf.Write(sum[:])
+	writeString(&buf, string(policy))
+
+	writeUvarint(&buf, uint64(len(table.Airports)))
+	for i := range table.Airports {
+		airport := &table.Airports[i]
+		for _, field := range airportFields(airport) {
+			writeString(&buf, *field)
+		}
+		writeFloat(&buf, airport.Position.Latitude)
+		writeFloat(&buf, airport.Position.Longitude)
+		columns := slices.Sorted(maps.Keys(airport.Extra))
+		writeUvarint(&buf, uint64(len(columns)))
+		for _, column := range columns {
+			writeString(&buf, column)
+			writeString(&buf, airport.Extra[column])
+		}
+	}
+
+	// City-prefixed keys always mirror the plain ones, so only the plain keys are stored
+	var plain []string
+	for code := range table.Codes {
+		if !strings.HasPrefix(code, "*") {
+			plain = append(plain, code)
+		}
+	}
+	writeUvarint(&buf, uint64(len(plain)))
+	for _, code := range plain {
+		writeString(&buf, code)
+		writeUvarint(&buf, uint64(table.Codes[code]))
+	}
+
+	writeUvarint(&buf, uint64(len(table.Conflicts)))
+	for _, conflict := range table.Conflicts {
+		writeString(&buf, conflict.Code)
+		writeString(&buf, string(conflict.Kind))
+		for _, row := range []Row{conflict.First, conflict.Second} {
+			writeString(&buf, row.File)
+			writeUvarint(&buf, uint64(row.Line))
+			writeString(&buf, row.Airport.Name)
+			writeString(&buf, row.Airport.ISOCountry)
+		}
+	}
+	return buf.Bytes()
+}
+
+func decodeIndex(data []byte) ([32]byte, DuplicatePolicy, *Table, error) {
+	var sum [32]byte
+	if len(data) < len(indexMagic)+len(sum) || string(data[:len(indexMagic)]) != indexMagic {
+		return sum, "", nil, errBadIndex
+	}
+	copy(sum[:], data[len(indexMagic):])
+
+	// Decoded strings share one backing copy of the file instead of one allocation each
+	d := &indexDecoder{text: string(data[len(indexMagic)+len(sum):])}
+	policy := DuplicatePolicy(d.string())
+
+	table := &Table{Airports: make([]types.Airport, d.count())}
+	for i := range table.Airports {
+		airport := &table.Airports[i]
+		for _, field := range airportFields(airport) {
+			*field = d.string()
+		}
+		airport.Position.Latitude = d.float()
+		airport.Position.Longitude = d.float()
+		if columns := d.count(); columns > 0 {
+			airport.Extra = make(map[string]string, columns)
+			for j := 0; j < columns && d.err == nil; j++ {
+				column := d.string()
+				airport.Extra[column] = d.string()
+			}
+		}
+	}
+
+	codes := d.count()
+	table.Codes = make(map[string]int, 2*codes)
+	for j := 0; j < codes && d.err == nil; j++ {
+		code := d.string()
+		// Compared before the conversion, which could turn a huge value negative
+		i := d.uvarint()
+		if i >= uint64(len(table.Airports)) {
+			return sum, "", nil, errBadIndex
+		}
+		table.Codes[code] = int(i)
+		table.Codes["*"+code] = int(i)
+	}
+
+	if conflicts := d.count(); conflicts > 0 {
+		table.Conflicts = make([]Conflict, conflicts)
+		for j := 0; j < conflicts && d.err == nil; j++ {
+			conflict := &table.Conflicts[j]
+			conflict.Code = d.string()
+			conflict.Kind = ConflictKind(d.string())
+			for _, row := range []*Row{&conflict.First, &conflict.Second} {
+				row.File = d.string()
+				line := d.uvarint()
+				if line > math.MaxInt32 {
+					d.err = errBadIndex
+				}
+				row.Line = int(line)
+				row.Airport.Name = d.string()
+				row.Airport.ISOCountry = d.string()
+			}
+		}
+	}
+	if d.err != nil {
+		return sum, "", nil, d.err
+	}
+	return sum, policy, table, nil
+}
+
+var errBadIndex = errors.New("corrupt airport index")
+
+func writeUvarint(buf *bytes.Buffer, value uint64) {
+	buf.Write(binary.AppendUvarint(nil, value))
+}
+
+func writeString(buf *bytes.Buffer, value string) {
+	writeUvarint(buf, uint64(len(value)))
+	buf.WriteString(value)
+}
+
+func writeFloat(buf *bytes.Buffer, value float64) {
+	buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(value)))
+}
+
+type indexDecoder struct {
+	text string
+	pos  int
+	err  error
+}
+
+func (d *indexDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	var value uint64
+	for shift := 0; shift < 64; shift += 7 {
+		if d.pos >= len(d.text) {
+			break
+		}
+		b := d.text[d.pos]
+		d.pos++
+		value |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return value
+		}
+	}
+	d.err = errBadIndex
+	return 0
+}
+
+// count reads a length prefix, bounded by the bytes left so corrupt input cannot force huge allocations
+func (d *indexDecoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.text)-d.pos) {
+		d.err = errBadIndex
+		return 0
+	}
+	return int(n)
+}
+
+func (d *indexDecoder) string() string {
+	n := d.count()
+	if d.err != nil {
+		return ""
+	}
+	value := d.text[d.pos : d.pos+n]
+	d.pos += n
+	return value
+}
+
+func (d *indexDecoder) float() float64 {
+	if d.err != nil || len(d.text)-d.pos < 8 {
+		d.err = errBadIndex
+		return 0
+	}
+	bits := binary.LittleEndian.Uint64([]byte(d.text[d.pos : d.pos+8]))
+	d.pos += 8
+	return math.Float64frombits(bits)
+}
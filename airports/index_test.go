@@ -0,0 +1,52 @@
+package airports
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexKeepsConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.csv")
+	content := `name,iso_country,municipality,icao_code,iata_code,coordinates
+"London Heathrow Airport","GB","London","EGLL","LHR","51.4706,-0.4619"
+"Fake Heathrow","FR","Paris","LFPG","LHR","49.0,2.5"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBinaryIndexer(NewCSVParser()).Build(path); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, ok := readIndex(path, LastWins); !ok {
+		t.Fatal("readIndex() ignored a fresh index")
+	}
+
+	loader := NewAirportLoader(NewCSVParser())
+	if _, err := loader.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	conflicts := loader.Conflicts()
+	want := `country mismatch #LHR: ` + path + `:2 "London Heathrow Airport" (GB) and ` + path + `:3 "Fake Heathrow" (FR)`
+	if len(conflicts) != 1 || conflicts[0].Error() != want {
+		t.Errorf("Conflicts() = %v, want [%s]", conflicts, want)
+	}
+}
+
+func TestDecodeIndexRejectsOutOfRangePositions(t *testing.T) {
+	var sum [32]byte
+	data := encodeIndex(sum, LastWins, &Table{Codes: map[string]int{}})
+	// Replace the empty key list with one key pointing far past the airports
+	data = data[:len(data)-2]
+	data = binary.AppendUvarint(data, 1)
+	data = binary.AppendUvarint(data, 4)
+	data = append(data, "#LHR"...)
+	data = binary.AppendUvarint(data, math.MaxUint64)
+	data = binary.AppendUvarint(data, 0)
+
+	if _, _, _, err := decodeIndex(data); err != errBadIndex {
+		t.Errorf("decodeIndex() error = %v, want %v", err, errBadIndex)
+	}
+}
@@ -1,34 +1,136 @@
 package airports
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"itinerary-prettifier/types"
 )
+
+// AirportRepository stores every airport once; lookup keys hold its position
 type AirportRepository struct {
-	airports map[string]types.Airport
+	airports []types.Airport
+	sources  []string // lookup file of each airport
+	codes    map[string]int
 }
 
 func NewAirportRepository(airports map[string]types.Airport) *AirportRepository {
-	return &AirportRepository{airports: airports}
+	repo := newEmptyRepository()
+	for code, airport := range airports {
+		repo.codes[code] = len(repo.airports)
+		repo.airports = append(repo.airports, airport)
+		repo.sources = append(repo.sources, "")
+	}
+	return repo
+}
+
+func newEmptyRepository() *AirportRepository {
+	return &AirportRepository{codes: make(map[string]int)}
 }
 
 func (r *AirportRepository) FindByCode(code string) (*types.Airport, bool) {
-	airport, exists := r.airports[code]
-	return &airport, exists
+	i, exists := r.codes[code]
+	if !exists {
+		return &types.Airport{}, false
+	}
+	return &r.airports[i], true
+}
+
+// FindPosition returns the parsed coordinates of the airport for code
+func (r *AirportRepository) FindPosition(code string) (types.Position, bool) {
+	i, exists := r.codes[code]
+	if !exists || r.airports[i].Coordinates == "" && r.airports[i].Position == (types.Position{}) {
+		// An airport from an overlay layer may come without coordinates
+		return types.Position{}, false
+	}
+	return r.airports[i].Position, true
 }
 
+// GetAll expands the repository into one airport copy per lookup key
 func (r *AirportRepository) GetAll() map[string]types.Airport {
-	return r.airports
+	table := &Table{Airports: r.airports, Codes: r.codes}
+	return table.Map()
+}
+
+// SourceOf reports which lookup file supplied the airport for code
+func (r *AirportRepository) SourceOf(code string) (string, bool) {
+	i, exists := r.codes[code]
+	if !exists {
+		return "", false
+	}
+	return r.sources[i], true
+}
+
+// merge adds every airport from table, overriding codes already present. An
+// airport whose IATA or ICAO code is already present amends that record:
+// its non-blank fields replace the earlier ones and the rest fall through.
+func (r *AirportRepository) merge(table *Table, source string) {
+	offset := len(r.airports)
+	for _, airport := range table.Airports {
+		base, exists := r.codes["#"+airport.IATA]
+		if !exists || airport.IATA == "" {
+			base, exists = r.codes["##"+airport.ICAO]
+			exists = exists && airport.ICAO != ""
+		}
+		if exists {
+			airport = amendAirport(r.airports[base], airport)
+			// Codes the amending row does not name still find the amended record
+			for _, code := range lookupKeys(airport) {
+				if i, taken := r.codes[code]; taken && i == base {
+					r.codes[code] = len(r.airports)
+				}
+			}
+		}
+		r.airports = append(r.airports, airport)
+		r.sources = append(r.sources, source)
+	}
+	for code, i := range table.Codes {
+		r.codes[code] = offset + i
+	}
+}
+
+// amendAirport returns base with every non-blank field of override applied
+func amendAirport(base, override types.Airport) types.Airport {
+	amended := base
+	for _, pair := range [][2]*string{
+		{&amended.Name, &override.Name},
+		{&amended.ISOCountry, &override.ISOCountry},
+		{&amended.Municipality, &override.Municipality},
+		{&amended.ICAO, &override.ICAO},
+		{&amended.IATA, &override.IATA},
+		{&amended.TimeZone, &override.TimeZone},
+	} {
+		if *pair[1] != "" {
+			*pair[0] = *pair[1]
+		}
+	}
+	if override.Coordinates != "" {
+		amended.Coordinates, amended.Position = override.Coordinates, override.Position
+	}
+	if len(override.Extra) > 0 {
+		amended.Extra = make(map[string]string, len(base.Extra)+len(override.Extra))
+		for column, value := range base.Extra {
+			amended.Extra[column] = value
+		}
+		for column, value := range override.Extra {
+			if value != "" {
+				amended.Extra[column] = value
+			}
+		}
+	}
+	return amended
 }
 
 // Loader handles loading airport data from file
 type Loader interface {
 	Load(lookupPath string) (Repository, error)
+	LoadLayered(lookupPaths []string) (Repository, error)
+	Conflicts() []Conflict
 }
 
 type AirportLoader struct {
-	parser Parser
+	parser    Parser
+	conflicts []Conflict
 }
 
 func NewAirportLoader(parser Parser) *AirportLoader {
@@ -36,16 +138,59 @@ func NewAirportLoader(parser Parser) *AirportLoader {
 }
 
 func (l *AirportLoader) Load(lookupPath string) (Repository, error) {
+	return l.LoadLayered([]string{lookupPath})
+}
+
+// LoadLayered builds one repository from several lookup files. Files are
+// applied in order: the first must be a complete lookup, and each later one
+// amends it field by field, so a blank or missing column in a later file
+// keeps the value from an earlier one.
+func (l *AirportLoader) LoadLayered(lookupPaths []string) (Repository, error) {
+	repo := newEmptyRepository()
+	for i, path := range lookupPaths {
+		table, err := l.parseFile(path, i > 0)
+		if err != nil {
+			return nil, err
+		}
+		repo.merge(table, path)
+	}
+	return repo, nil
+}
+
+// Conflicts returns the code collisions of every file loaded so far, whether
+// it was read from the CSV or from its index
+func (l *AirportLoader) Conflicts() []Conflict {
+	return l.conflicts
+}
+
+// parseFile prefers an up-to-date binary index and falls back to the CSV,
+// which is read as an overlay when it amends an earlier layer
+func (l *AirportLoader) parseFile(lookupPath string, overlay bool) (*Table, error) {
+	if table, ok := readIndex(lookupPath, l.parser.DuplicatePolicy()); ok {
+		l.conflicts = append(l.conflicts, table.Conflicts...)
+		return table, nil
+	}
+
 	file, err := os.Open(lookupPath)
 	if err != nil {
 		return nil, fmt.Errorf("airport lookup not found: %w", err)
 	}
 	defer file.Close()
 
-	airports, err := l.parser.Parse(file)
+	parse := l.parser.ParseTable
+	if overlay {
+		parse = l.parser.ParseOverlay
+	}
+	table, err := parse(file)
 	if err != nil {
-		return nil, fmt.Errorf("airport lookup malformed: %w", err)
+		// The collision that aborted the load is reported with the rest
+		var conflict Conflict
+		if errors.As(err, &conflict) {
+			l.conflicts = append(l.conflicts, conflict)
+		}
+		return nil, fmt.Errorf("airport lookup malformed: %s: %w", lookupPath, err)
 	}
+	l.conflicts = append(l.conflicts, table.Conflicts...)
 
-	return NewAirportRepository(airports), nil
+	return table, nil
 }
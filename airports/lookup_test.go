@@ -0,0 +1,64 @@
+package airports
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeLookup(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayeredAmendsFieldByField(t *testing.T) {
+	base := writeLookup(t, "base.csv", `name,iso_country,municipality,icao_code,iata_code,coordinates,time_zone,lounge
+"London Heathrow Airport","GB","London","EGLL","LHR","51.4706,-0.4619","Europe/London","Plaza"
+"John F Kennedy International Airport","US","New York","KJFK","JFK","40.6398,-73.7789","America/New_York","T4"
+`)
+	overrides := writeLookup(t, "ov.csv", `iata_code,icao_code,name,lounge
+"LHR","","Heathrow",""
+"","KJFK","JFK","Wing"
+"ZZZ","","Nowhere",""
+`)
+
+	repo, err := NewAirportLoader(NewCSVParser()).LoadLayered([]string{base, overrides})
+	if err != nil {
+		t.Fatalf("LoadLayered() error = %v", err)
+	}
+	for _, code := range []string{"#LHR", "*#LHR", "##EGLL"} {
+		airport, _ := repo.FindByCode(code)
+		if airport.Name != "Heathrow" || airport.Municipality != "London" || airport.TimeZone != "Europe/London" || airport.Extra["lounge"] != "Plaza" {
+			t.Errorf("FindByCode(%q) = %+v", code, *airport)
+		}
+	}
+	if position, ok := repo.FindPosition("#LHR"); !ok || position.Latitude != 51.4706 {
+		t.Errorf("FindPosition(#LHR) = %v, %v", position, ok)
+	}
+	airport, _ := repo.FindByCode("#JFK")
+	if airport.Name != "JFK" || airport.TimeZone != "America/New_York" || airport.Extra["lounge"] != "Wing" {
+		t.Errorf("FindByCode(#JFK) = %+v", *airport)
+	}
+	if source, _ := repo.(*AirportRepository).SourceOf("#JFK"); source != overrides {
+		t.Errorf("SourceOf(#JFK) = %q, want %q", source, overrides)
+	}
+
+	// A new airport from an overlay has only what the overlay gives it
+	if airport, exists := repo.FindByCode("#ZZZ"); !exists || airport.Name != "Nowhere" {
+		t.Errorf("FindByCode(#ZZZ) = %+v, %v", *airport, exists)
+	}
+	if _, ok := repo.FindPosition("#ZZZ"); ok {
+		t.Error("FindPosition(#ZZZ) reported coordinates the overlay never gave")
+	}
+}
+
+func TestLoadLayeredRequiresACompleteFirstLayer(t *testing.T) {
+	partial := writeLookup(t, "ov.csv", "iata_code,name\n\"LHR\",\"Heathrow\"\n")
+	if _, err := NewAirportLoader(NewCSVParser()).LoadLayered([]string{partial}); err == nil {
+		t.Error("LoadLayered() accepted a partial file as the base layer")
+	}
+}
@@ -6,25 +6,123 @@ import (
 	"io"
 	"itinerary-prettifier/types"
 	"os"
+	"slices"
 	"strings"
 )
 
 // Parser handles CSV parsing for airport data
 type Parser interface {
 	Parse(file *os.File) (map[string]types.Airport, error)
+	ParseTable(file *os.File) (*Table, error)
+	ParseOverlay(file *os.File) (*Table, error)
+	DuplicatePolicy() DuplicatePolicy
+}
+
+// Table holds each airport once; every lookup key points at its slot in Airports
+type Table struct {
+	Airports  []types.Airport
+	Codes     map[string]int
+	Conflicts []Conflict // code collisions found while building the table
+}
+
+// Map expands the table into one airport copy per lookup key
+func (t *Table) Map() map[string]types.Airport {
+	airportMap := make(map[string]types.Airport, len(t.Codes))
+	for code, i := range t.Codes {
+		airportMap[code] = t.Airports[i]
+	}
+	return airportMap
 }
 
 type CSVParser struct {
 	requiredColumns []string
+	duplicatePolicy DuplicatePolicy
+	conflicts       []Conflict
 }
 
 func NewCSVParser() *CSVParser {
 	return &CSVParser{
-		requiredColumns: []string{"name", "iso_country", "municipality", "icao_code", "iata_code", "coordinates"},
+		requiredColumns: []string{"name", "iso_country", "municipality", "icao_code", "iata_code"},
+		duplicatePolicy: LastWins,
 	}
 }
 
+// SetDuplicatePolicy chooses what happens when two rows share a code
+func (p *CSVParser) SetDuplicatePolicy(policy DuplicatePolicy) {
+	p.duplicatePolicy = policy
+}
+
+// DuplicatePolicy returns the policy applied when two rows share a code
+func (p *CSVParser) DuplicatePolicy() DuplicatePolicy {
+	return p.duplicatePolicy
+}
+
+// Conflicts returns every code collision seen since the parser was created
+func (p *CSVParser) Conflicts() []Conflict {
+	return p.conflicts
+}
+
 func (p *CSVParser) Parse(file *os.File) (map[string]types.Airport, error) {
+	table, err := p.ParseTable(file)
+	if err != nil {
+		return nil, err
+	}
+	return table.Map(), nil
+}
+
+// ParseTable reads the file into a Table that stores every airport once. A
+// single record that cannot be read fails the whole file.
+func (p *CSVParser) ParseTable(file *os.File) (*Table, error) {
+	return p.parseTable(file, false)
+}
+
+// ParseOverlay reads a file that amends an earlier lookup layer. Only the
+// header and an IATA or ICAO code on each row are required; any other
+// column may be missing or blank, leaving that field empty for the merge to
+// fill in from the earlier layer.
+func (p *CSVParser) ParseOverlay(file *os.File) (*Table, error) {
+	return p.parseTable(file, true)
+}
+
+func (p *CSVParser) parseTable(file *os.File, partial bool) (*Table, error) {
+	rows, err := p.parseRows(file, partial)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, row.Err)
+		}
+	}
+	return p.buildTable(rows)
+}
+
+// buildTable indexes rows under their lookup keys, applying the duplicate policy
+func (p *CSVParser) buildTable(rows []Row) (*Table, error) {
+	table := &Table{
+		Airports: make([]types.Airport, len(rows)),
+		Codes:    make(map[string]int, 4*len(rows)),
+	}
+	seen := len(p.conflicts)
+	for i, row := range rows {
+		table.Airports[i] = row.Airport
+		if err := p.addAirportToMap(table, rows, i); err != nil {
+			return nil, err
+		}
+	}
+	table.Conflicts = p.conflicts[seen:]
+
+	return table, nil
+}
+
+// ParseRows reads every record in file order without building the code map.
+// Records that cannot be read are returned with Err set; only a bad header or
+// broken CSV syntax stops the read.
+func (p *CSVParser) ParseRows(file *os.File) ([]Row, error) {
+	return p.parseRows(file, false)
+}
+
+func (p *CSVParser) parseRows(file *os.File, partial bool) ([]Row, error) {
 	reader := csv.NewReader(file)
 
 	headers, err := reader.Read()
@@ -32,13 +130,12 @@ func (p *CSVParser) Parse(file *os.File) (map[string]types.Airport, error) {
 		return nil, fmt.Errorf("failed to read header: %w", err)
 	}
 
-	columnMap, err := p.validateHeaders(headers)
+	columnMap, columns, err := p.validateHeaders(headers, partial)
 	if err != nil {
 		return nil, err
 	}
 
-	airportMap := make(map[string]types.Airport)
-
+	var rows []Row
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
@@ -47,65 +144,193 @@ func (p *CSVParser) Parse(file *os.File) (map[string]types.Airport, error) {
 		if err != nil {
 			return nil, fmt.Errorf("error reading record: %w", err)
 		}
+		line, _ := reader.FieldPos(0)
 
-		airport, err := p.parseRecord(record, columnMap)
-		if err != nil {
-			return nil, err
+		raw := make(map[string]string, len(columns))
+		for _, column := range columns {
+			if idx := columnMap[column]; idx < len(record) {
+				raw[column] = record[idx]
+			}
 		}
-
-		p.addAirportToMap(airportMap, airport)
+		for _, column := range timeZoneColumns {
+			if idx, exists := columnMap[column]; exists && idx < len(record) {
+				raw[column] = record[idx]
+				break
+			}
+		}
+		row := Row{File: file.Name(), Line: line, Raw: raw}
+		if airport, err := p.parseRecord(record, columnMap, columns, partial); err != nil {
+			row.Err = err
+		} else {
+			row.Airport = *airport
+		}
+		rows = append(rows, row)
 	}
 
-	return airportMap, nil
+	return rows, nil
+}
+
+// coordinateLayouts lists the accepted ways of storing an airport's position:
+// one "lat,lon" column or separate latitude and longitude columns
+var coordinateLayouts = [][]string{
+	{"coordinates"},
+	{"latitude_deg", "longitude_deg"},
+	{"latitude", "longitude"},
 }
 
-func (p *CSVParser) validateHeaders(headers []string) (map[string]int, error) {
+// timeZoneColumns are the accepted names of the optional IANA time zone column
+var timeZoneColumns = []string{"time_zone", "timezone", "tz"}
+
+// validateHeaders maps header names to positions and returns the columns every
+// record must fill, including the coordinate columns of the layout in use. A
+// partial file may leave out any of them but needs a code column.
+func (p *CSVParser) validateHeaders(headers []string, partial bool) (map[string]int, []string, error) {
 	columnMap := make(map[string]int)
 	for i, header := range headers {
 		cleanHeader := strings.TrimSpace(strings.ToLower(header))
 		columnMap[cleanHeader] = i
 	}
 
-	for _, required := range p.requiredColumns {
-		if _, exists := columnMap[required]; !exists {
-			return nil, fmt.Errorf("missing required column: %s", required)
+	var required []string
+	for _, column := range p.requiredColumns {
+		if _, exists := columnMap[column]; exists {
+			required = append(required, column)
+		} else if !partial {
+			return nil, nil, fmt.Errorf("missing required column: %s", column)
 		}
 	}
+	if !slices.Contains(required, "iata_code") && !slices.Contains(required, "icao_code") {
+		return nil, nil, fmt.Errorf("missing required column: iata_code or icao_code")
+	}
 
-	return columnMap, nil
+	for _, layout := range coordinateLayouts {
+		present := true
+		for _, column := range layout {
+			if _, exists := columnMap[column]; !exists {
+				present = false
+			}
+		}
+		if present {
+			return columnMap, append(required, layout...), nil
+		}
+	}
+	if partial {
+		return columnMap, required, nil
+	}
+
+	return nil, nil, fmt.Errorf("missing required column: coordinates (or latitude_deg and longitude_deg)")
 }
 
-func (p *CSVParser) parseRecord(record []string, columnMap map[string]int) (*types.Airport, error) {
+func (p *CSVParser) parseRecord(record []string, columnMap map[string]int, columns []string, partial bool) (*types.Airport, error) {
 	// Check if record has enough columns
-	if len(record) < len(p.requiredColumns) {
+	if len(record) < len(columns) {
 		return nil, fmt.Errorf("record has insufficient columns")
 	}
 
-	// Validate no blank fields in required columns
-	for _, required := range p.requiredColumns {
-		idx := columnMap[required]
-		if idx >= len(record) || strings.TrimSpace(record[idx]) == "" {
-			return nil, fmt.Errorf("blank field in required column: %s", required)
+	field := func(column string) string {
+		if idx, exists := columnMap[column]; exists && idx < len(record) {
+			return strings.TrimSpace(record[idx])
+		}
+		return ""
+	}
+
+	// Validate no blank fields in required columns; a partial row only needs a code
+	if partial {
+		if field("iata_code") == "" && field("icao_code") == "" {
+			return nil, fmt.Errorf("blank field in required column: iata_code or icao_code")
 		}
+	} else {
+		for _, required := range columns {
+			if field(required) == "" {
+				return nil, fmt.Errorf("blank field in required column: %s", required)
+			}
+		}
+	}
+
+	// The coordinate columns, if any, come last
+	coordinates := ""
+	switch last := columns[len(columns)-1]; {
+	case last == "coordinates":
+		coordinates = field(last)
+	case last == "longitude_deg" || last == "longitude":
+		if latitude, longitude := field(columns[len(columns)-2]), field(last); latitude != "" || longitude != "" {
+			coordinates = latitude + "," + longitude
+		}
+	}
+	var position types.Position
+	if coordinates != "" || !partial {
+		var err error
+		if position, err = types.ParsePosition(coordinates); err != nil {
+			return nil, fmt.Errorf("invalid coordinates: %w", err)
+		}
+	}
+
+	timeZone := ""
+	for _, column := range timeZoneColumns {
+		if idx, exists := columnMap[column]; exists && idx < len(record) {
+			timeZone = strings.TrimSpace(record[idx])
+			break
+		}
+	}
+
+	// Every other column is kept under its header, including the coordinate
+	// and zone columns read above, so field tokens can name any column of
+	// the file. Only those an airport field already holds under the same
+	// name are left out.
+	var extra map[string]string
+	for column, idx := range columnMap {
+		if idx >= len(record) || slices.Contains(p.requiredColumns, column) || column == "coordinates" || column == "time_zone" {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]string)
+		}
+		extra[column] = strings.TrimSpace(record[idx])
 	}
 
 	return &types.Airport{
-		Name:         strings.TrimSpace(record[columnMap["name"]]),
-		ISOCountry:   strings.TrimSpace(record[columnMap["iso_country"]]),
-		Municipality: strings.TrimSpace(record[columnMap["municipality"]]),
-		ICAO:         strings.TrimSpace(record[columnMap["icao_code"]]),
-		IATA:         strings.TrimSpace(record[columnMap["iata_code"]]),
-		Coordinates:  strings.TrimSpace(record[columnMap["coordinates"]]),
+		Name:         field("name"),
+		ISOCountry:   field("iso_country"),
+		Municipality: field("municipality"),
+		ICAO:         field("icao_code"),
+		IATA:         field("iata_code"),
+		Coordinates:  coordinates,
+		Position:     position,
+		TimeZone:     timeZone,
+		Extra:        extra,
 	}, nil
 }
 
-func (p *CSVParser) addAirportToMap(airportMap map[string]types.Airport, airport *types.Airport) {
+func (p *CSVParser) addAirportToMap(table *Table, rows []Row, i int) error {
+	row := rows[i]
+	for _, key := range lookupKeys(row.Airport) {
+		existing, taken := table.Codes[key]
+		if taken {
+			// The city-prefixed keys mirror the plain ones, so report each collision once
+			if !strings.HasPrefix(key, "*") {
+				conflict := newConflict(key, rows[existing], row)
+				p.conflicts = append(p.conflicts, conflict)
+				if p.duplicatePolicy == FailOnDuplicate {
+					return conflict
+				}
+			}
+			if p.duplicatePolicy == FirstWins {
+				continue
+			}
+		}
+		table.Codes[key] = i
+	}
+	return nil
+}
+
+// lookupKeys lists the plain and city-prefixed codes an airport is found under
+func lookupKeys(airport types.Airport) []string {
+	var keys []string
 	if airport.IATA != "" {
-		airportMap["#"+airport.IATA] = *airport
-		airportMap["*#"+airport.IATA] = *airport
+		keys = append(keys, "#"+airport.IATA, "*#"+airport.IATA)
 	}
 	if airport.ICAO != "" {
-		airportMap["##"+airport.ICAO] = *airport
-		airportMap["*##"+airport.ICAO] = *airport
+		keys = append(keys, "##"+airport.ICAO, "*##"+airport.ICAO)
 	}
+	return keys
 }
@@ -0,0 +1,221 @@
+package airports
+
+import (
+	"fmt"
+	"itinerary-prettifier/types"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Issue is a single data quality problem found in a lookup file
+type Issue struct {
+	Line    int
+	Column  string
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Column == "" {
+		return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Column, i.Message)
+}
+
+// Report summarises a lookup file validation run
+type Report struct {
+	Path   string
+	Rows   int
+	Issues []Issue
+}
+
+// OK reports whether the file passed every check
+func (r *Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// DatasetValidator checks a lookup file for data quality problems
+type DatasetValidator interface {
+	Validate(lookupPath string) (*Report, error)
+}
+
+type LookupValidator struct {
+	parser *CSVParser
+}
+
+func NewLookupValidator(parser *CSVParser) *LookupValidator {
+	return &LookupValidator{parser: parser}
+}
+
+var (
+	iataPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+	icaoPattern = regexp.MustCompile(`^[A-Z0-9]{4}$`)
+)
+
+// Validate runs the file through the CSV parser and checks every row. Records
+// the parser cannot read are reported as issues alongside the rest; only a
+// file without a usable header or with broken CSV syntax is returned as an error.
+func (v *LookupValidator) Validate(lookupPath string) (*Report, error) {
+	file, err := os.Open(lookupPath)
+	if err != nil {
+		return nil, fmt.Errorf("airport lookup not found: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := v.parser.ParseRows(file)
+	if err != nil {
+		return nil, fmt.Errorf("airport lookup malformed: %w", err)
+	}
+
+	report := &Report{Path: lookupPath, Rows: len(rows)}
+	for i, row := range rows {
+		report.Issues = append(report.Issues, v.checkRow(row)...)
+		if row.Err != nil {
+			// Unreadable rows still take part in the duplicate check with the codes as written
+			rows[i].Airport = rawAirport(row)
+		}
+	}
+
+	// Duplicates are found by building the code map exactly as Load would
+	scratch := NewCSVParser()
+	scratch.buildTable(rows)
+	for _, conflict := range scratch.Conflicts() {
+		report.Issues = append(report.Issues, Issue{
+			Line:    conflict.Second.Line,
+			Message: fmt.Sprintf("%s %s also defined on line %d", conflict.Kind, conflict.Code, conflict.First.Line),
+		})
+	}
+
+	return report, nil
+}
+
+func (v *LookupValidator) checkRow(row Row) []Issue {
+	var issues []Issue
+	add := func(column, format string, args ...interface{}) {
+		issues = append(issues, Issue{Line: row.Line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Every check reads the fields as written, so a row the parser rejected
+	// is checked as thoroughly as the rest
+	field := func(column string) string {
+		return strings.TrimSpace(row.Raw[column])
+	}
+	for _, column := range v.parser.requiredColumns {
+		if field(column) == "" {
+			add(column, "blank field in required column")
+		}
+	}
+
+	if iata := field("iata_code"); iata != "" && !iataPattern.MatchString(iata) {
+		add("iata_code", "%q is not three uppercase letters", iata)
+	}
+	if icao := field("icao_code"); icao != "" && !icaoPattern.MatchString(icao) {
+		add("icao_code", "%q is not four uppercase letters or digits", icao)
+	}
+	if country := field("iso_country"); country != "" && !IsISOCountry(country) {
+		add("iso_country", "%q is not an ISO 3166-1 alpha-2 code", country)
+	}
+	for _, layout := range coordinateLayouts {
+		if _, exists := row.Raw[layout[0]]; !exists {
+			continue
+		}
+		values := make([]string, len(layout))
+		for i, column := range layout {
+			if values[i] = field(column); values[i] == "" {
+				add(column, "blank field in required column")
+			}
+		}
+		if !slices.Contains(values, "") {
+			if _, err := types.ParsePosition(strings.Join(values, ",")); err != nil {
+				add(strings.Join(layout, ","), "invalid coordinates: %v", err)
+			}
+		}
+		break
+	}
+	for _, column := range timeZoneColumns {
+		if zone := field(column); zone != "" {
+			if _, err := time.LoadLocation(zone); err != nil {
+				add(column, "%q is not an IANA time zone", zone)
+			}
+		}
+	}
+
+	if len(issues) == 0 && row.Err != nil {
+		// A problem the field checks above do not cover
+		add("", "%v", row.Err)
+	}
+	return append(issues, rawTextIssues(row)...)
+}
+
+// rawAirport fills the fields the duplicate check compares from the row as written
+func rawAirport(row Row) types.Airport {
+	field := func(column string) string {
+		return strings.TrimSpace(row.Raw[column])
+	}
+	return types.Airport{
+		Name:       field("name"),
+		ISOCountry: field("iso_country"),
+		IATA:       field("iata_code"),
+		ICAO:       field("icao_code"),
+	}
+}
+
+// rawTextIssues checks the required columns of row as written in the file
+func rawTextIssues(row Row) []Issue {
+	var issues []Issue
+	columns := make([]string, 0, len(row.Raw))
+	for column := range row.Raw {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		for _, problem := range textProblems(row.Raw[column]) {
+			issues = append(issues, Issue{Line: row.Line, Column: column, Message: problem})
+		}
+	}
+	return issues
+}
+
+// textProblems lists whitespace and encoding defects in a raw field
+func textProblems(value string) []string {
+	var problems []string
+	if !utf8.ValidString(value) {
+		return append(problems, "invalid UTF-8")
+	}
+	if value != strings.TrimSpace(value) {
+		problems = append(problems, "leading or trailing whitespace")
+	}
+	if strings.Contains(value, "  ") {
+		problems = append(problems, "repeated spaces")
+	}
+	for _, r := range value {
+		if problem := runeProblem(r); problem != "" {
+			problems = append(problems, problem)
+			break
+		}
+	}
+	if mojibakePattern.MatchString(value) {
+		problems = append(problems, "looks like UTF-8 that was decoded as Latin-1")
+	}
+	return problems
+}
+
+// UTF-8 text decoded as Latin-1 leaves pairs such as "Ã©" behind
+var mojibakePattern = regexp.MustCompile(`[ÃÂ][\x{80}-\x{BF}]`)
+
+func runeProblem(r rune) string {
+	switch {
+	case r == utf8.RuneError:
+		return "contains the U+FFFD replacement character"
+	case r == '\u00A0' || r == '\uFEFF' || r == '\u200B':
+		return fmt.Sprintf("contains invisible character %U", r)
+	case unicode.IsControl(r):
+		return fmt.Sprintf("contains control character %U", r)
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package airports
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestValidateReportsUnreadableRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lookup.csv")
+	content := `name,iso_country,municipality,icao_code,iata_code,coordinates
+"Los Angeles International Airport","US","Los Angeles","KLAX","LAX","33.9425,-118.4081"
+"John F Kennedy International Airport","US","New York","KJFK","JFK","95,-73.7789"
+"","US","San Francisco","KSFO","SFO","37.6189,-122.3750"
+"Heathrow ","GB","London","EGLL","LHR","51.4706,-0.4619"
+"Heathrow Again","XX","London","EGLL","lhr","91,0"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := NewLookupValidator(NewCSVParser()).Validate(path)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"line 3: coordinates: invalid coordinates: latitude 95 is outside -90..90",
+		"line 4: name: blank field in required column",
+		"line 5: name: leading or trailing whitespace",
+		`line 6: iata_code: "lhr" is not three uppercase letters`,
+		`line 6: iso_country: "XX" is not an ISO 3166-1 alpha-2 code`,
+		"line 6: coordinates: invalid coordinates: latitude 91 is outside -90..90",
+		"line 6: country mismatch ##EGLL also defined on line 5",
+	}
+	if report.Rows != 5 || !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %d rows, %q; want 5 rows, %q", report.Rows, got, want)
+	}
+
+	// Prettifying still refuses the file
+	if _, err := NewAirportLoader(NewCSVParser()).Load(path); err == nil {
+		t.Error("Load() accepted a lookup with unreadable rows")
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"itinerary-prettifier/formatter"
 	"itinerary-prettifier/types"
 	"os"
+	"strings"
+	"time"
 )
 
 // Parser handles command line argument parsing
@@ -21,27 +24,128 @@ func NewCLIParser() *CLIParser {
 
 func (p *CLIParser) Parse() (*types.Config, error) {
 	helpFlag := flag.Bool("h", false, "show usage information")
+	var lookups stringList
+	flag.Var(&lookups, "lookup", "additional airport lookup CSV; later files override earlier ones")
+	listSources := flag.Bool("list-sources", false, "print which lookup file each resolved code came from")
+	onDuplicate := flag.String("on-duplicate", "last", "which row keeps a duplicated code: first, last or error")
+	distanceUnit := flag.String("distance-unit", "km", "unit for DIST tokens: km, mi or nm")
+	minLayover := flag.Duration("min-layover", time.Hour, "LAY tokens shorter than this are flagged as short connections")
+	dayMarkers := flag.Bool("day-markers", true, "mark arrival times that fall on a later day than the line's departure")
+	locale := flag.String("locale", "en", "language for dates and times: en, de, fr, es, et or ja")
+	dateLayout := flag.String("date-layout", "", "output pattern for D tokens, e.g. \"dddd D MMM YYYY\"")
+	time12Layout := flag.String("time12-layout", "", "output pattern for T12 tokens, e.g. \"h:mma\"")
+	time24Layout := flag.String("time24-layout", "", "output pattern for T24 tokens, e.g. \"HH:mm:ss (Z)\"")
+	dateTimeLayout := flag.String("datetime-layout", "", "output pattern for DT tokens, e.g. \"D MMM YYYY HH:mm Z\"")
+	now := flag.String("now", "", "reference time for REL tokens as an ISO 8601 timestamp; defaults to the system clock")
+	checkChronology := flag.Bool("check-chronology", false, "warn about segments and dates that are out of order, fall in DST transitions or carry the wrong offset for their airport")
+	markdown := flag.Bool("markdown", false, "treat the input as markdown and leave code and block structure untouched")
+	whitespace := flag.String("whitespace", "aggressive", "whitespace profile: preserve, normalize or aggressive")
+	lineEnding := flag.String("line-endings", "auto", "output line endings: auto (same as input), lf, crlf or cr")
+	typography := flag.Bool("typography", false, "use curly quotes, en and em dashes and ellipses outside tokens")
+	wrapWidth := flag.Int("wrap", 0, "break lines longer than this many columns at word boundaries; 0 disables wrapping")
+	ascii := flag.String("ascii", "off", "transliterate to plain ASCII: off, names (airport names and cities) or all (the whole document)")
+	iataTemplate := flag.String("iata-template", formatter.DefaultIATATemplate, "how #ABC codes are written, e.g. \"{name} ({iata})\"")
+	icaoTemplate := flag.String("icao-template", formatter.DefaultICAOTemplate, "how ##ABCD codes are written, e.g. \"{name} [{icao}]\"")
+	cityTemplate := flag.String("city-template", formatter.DefaultCityTemplate, "how *#ABC and *##ABCD codes are written, e.g. \"{municipality}, {country}\"")
+	inputEncoding := flag.String("input-encoding", "auto", "input encoding: auto (UTF-8 unless a BOM says UTF-16), utf-8, utf-16le, utf-16be, windows-1252 or iso-8859-1")
+	outputEncoding := flag.String("output-encoding", "utf-8", "output encoding: utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252, iso-8859-1 or ascii")
 	flag.Parse()
 
 	if *helpFlag {
 		fmt.Println("itinerary usage:")
 		fmt.Println("go run . ./input.txt ./output.txt ./airport-lookup.csv")
+		fmt.Println("go run . validate-lookup ./airport-lookup.csv")
+		fmt.Println("go run . build-index ./airport-lookup.csv")
 		os.Exit(0)
 	}
 
 	args := flag.Args()
-	if len(args) != 3 {
+	if len(args) > 0 && (args[0] == types.CommandValidateLookup || args[0] == types.CommandBuildIndex) {
+		// Flags may also follow the command name
+		flag.CommandLine.Parse(args[1:])
+		lookups = append(lookups, flag.Args()...)
+		if len(lookups) == 0 {
+			return nil, ErrInvalidArguments
+		}
+		return &types.Config{
+			Command:     args[0],
+			LookupPaths: lookups,
+			OnDuplicate: *onDuplicate,
+		}, nil
+	}
+
+	switch {
+	case len(args) == 3:
+		// The positional lookup is the base layer; --lookup files override it
+		lookups = append(stringList{args[2]}, lookups...)
+	case len(args) == 2 && len(lookups) > 0:
+	default:
 		return nil, ErrInvalidArguments
 	}
 
+	clock, err := fixedClock(*now)
+	if err != nil {
+		return nil, err
+	}
+
 	return &types.Config{
-		InputPath:  args[0],
-		OutputPath: args[1],
-		LookupPath: args[2],
+		InputPath:      args[0],
+		OutputPath:     args[1],
+		LookupPaths:    lookups,
+		ListSources:    *listSources,
+		OnDuplicate:    *onDuplicate,
+		InputEncoding:  *inputEncoding,
+		OutputEncoding: *outputEncoding,
+		Format: types.FormatOptions{
+			DistanceUnit:      *distanceUnit,
+			MinLayover:        *minLayover,
+			DayMarkers:        *dayMarkers,
+			Locale:            *locale,
+			DateLayout:        *dateLayout,
+			Time12Layout:      *time12Layout,
+			Time24Layout:      *time24Layout,
+			DateTimeLayout:    *dateTimeLayout,
+			Clock:             clock,
+			CheckChronology:   *checkChronology,
+			Markdown:          *markdown,
+			WhitespaceProfile: *whitespace,
+			LineEnding:        *lineEnding,
+			SmartTypography:   *typography,
+			WrapWidth:         *wrapWidth,
+			ASCII:             *ascii,
+			IATATemplate:      *iataTemplate,
+			ICAOTemplate:      *icaoTemplate,
+			CityTemplate:      *cityTemplate,
+		},
 	}, nil
 }
 
+// fixedClock turns a --now value into a clock that always reports it
+func fixedClock(value string) (func() time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := formatter.ParseISO8601(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNow, err)
+	}
+	return func() time.Time { return parsed.Time }, nil
+}
+
+// stringList collects the values of a repeatable flag in the order given
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 // CLI errors
 var (
 	ErrInvalidArguments = errors.New("invalid number of arguments")
+	ErrInvalidNow       = errors.New("invalid --now timestamp")
 )
@@ -2,6 +2,9 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"itinerary-prettifier/fileio"
+	"itinerary-prettifier/formatter"
 	"itinerary-prettifier/types"
 )
 
@@ -17,15 +20,68 @@ func NewConfigValidator() *ConfigValidator {
 }
 
 func (v *ConfigValidator) Validate(config *types.Config) error {
-	if config.InputPath == "" {
-		return ErrInputPathRequired
+	if config.Command == types.CommandPrettify {
+		if config.InputPath == "" {
+			return ErrInputPathRequired
+		}
+		if config.OutputPath == "" {
+			return ErrOutputPathRequired
+		}
 	}
-	if config.OutputPath == "" {
-		return ErrOutputPathRequired
-	}
-	if config.LookupPath == "" {
+	if len(config.LookupPaths) == 0 {
 		return ErrLookupPathRequired
 	}
+	for _, path := range config.LookupPaths {
+		if path == "" {
+			return ErrLookupPathRequired
+		}
+	}
+	switch config.OnDuplicate {
+	case "", "first", "last", "error":
+	default:
+		return ErrInvalidDuplicatePolicy
+	}
+	switch config.Format.DistanceUnit {
+	case "", "km", "mi", "nm":
+	default:
+		return ErrInvalidDistanceUnit
+	}
+	if _, exists := fileio.ParseEncoding(config.InputEncoding); config.InputEncoding != "" && !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownEncoding, config.InputEncoding)
+	}
+	if encoding, exists := fileio.ParseEncoding(config.OutputEncoding); config.OutputEncoding != "" && (!exists || encoding == fileio.Auto) {
+		return fmt.Errorf("%w: %s", ErrUnknownEncoding, config.OutputEncoding)
+	}
+	if config.Format.MinLayover < 0 {
+		return ErrNegativeMinLayover
+	}
+	switch config.Format.ASCII {
+	case "", formatter.ASCIIOff, formatter.ASCIINames, formatter.ASCIIAll:
+	default:
+		return ErrUnknownASCIIMode
+	}
+	if config.Format.WrapWidth < 0 {
+		return ErrNegativeWrapWidth
+	}
+	if _, exists := formatter.LookupLocale(config.Format.Locale); config.Format.Locale != "" && !exists {
+		return ErrUnsupportedLocale
+	}
+	if _, exists := formatter.LookupWhitespaceProfile(config.Format.WhitespaceProfile); config.Format.WhitespaceProfile != "" && !exists {
+		return ErrUnknownWhitespaceProfile
+	}
+	if _, exists := formatter.ParseLineEnding(config.Format.LineEnding); !exists && config.Format.LineEnding != "" && config.Format.LineEnding != "auto" {
+		return ErrUnknownLineEnding
+	}
+	for _, template := range []string{config.Format.IATATemplate, config.Format.ICAOTemplate, config.Format.CityTemplate} {
+		if _, err := formatter.ParseAirportTemplate(template); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAirportTemplate, err)
+		}
+	}
+	for _, layout := range []string{config.Format.DateLayout, config.Format.Time12Layout, config.Format.Time24Layout, config.Format.DateTimeLayout} {
+		if _, err := formatter.ParseLayout(layout); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidLayout, err)
+		}
+	}
 	return nil
 }
 
@@ -34,4 +90,17 @@ var (
 	ErrInputPathRequired  = errors.New("input path is required")
 	ErrOutputPathRequired = errors.New("output path is required")
 	ErrLookupPathRequired = errors.New("lookup path is required")
+
+	ErrInvalidDuplicatePolicy = errors.New("duplicate policy must be first, last or error")
+	ErrInvalidDistanceUnit    = errors.New("distance unit must be km, mi or nm")
+	ErrNegativeMinLayover     = errors.New("minimum layover cannot be negative")
+	ErrNegativeWrapWidth      = errors.New("wrap width cannot be negative")
+	ErrUnsupportedLocale      = errors.New("unsupported locale")
+	ErrInvalidLayout          = errors.New("invalid output layout")
+	ErrInvalidAirportTemplate = errors.New("invalid airport template")
+
+	ErrUnknownWhitespaceProfile = errors.New("whitespace profile must be preserve, normalize or aggressive")
+	ErrUnknownLineEnding        = errors.New("line endings must be auto, lf, crlf or cr")
+	ErrUnknownEncoding          = errors.New("unsupported encoding")
+	ErrUnknownASCIIMode         = errors.New("ascii mode must be off, names or all")
 )
@@ -0,0 +1,171 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names a character encoding for input or output files
+type Encoding string
+
+const (
+	// Auto reads UTF-8 unless a byte order mark announces UTF-16
+	Auto        Encoding = "auto"
+	UTF8        Encoding = "utf-8"
+	UTF8BOM     Encoding = "utf-8-bom"
+	UTF16LE     Encoding = "utf-16le"
+	UTF16BE     Encoding = "utf-16be"
+	Windows1252 Encoding = "windows-1252"
+	ISO88591    Encoding = "iso-8859-1"
+	ASCII       Encoding = "ascii"
+)
+
+var encodingAliases = map[string]Encoding{
+	"auto":         Auto,
+	"utf-8":        UTF8,
+	"utf8":         UTF8,
+	"utf-8-bom":    UTF8BOM,
+	"utf8-bom":     UTF8BOM,
+	"utf-16le":     UTF16LE,
+	"utf16le":      UTF16LE,
+	"utf-16be":     UTF16BE,
+	"utf16be":      UTF16BE,
+	"windows-1252": Windows1252,
+	"cp1252":       Windows1252,
+	"iso-8859-1":   ISO88591,
+	"latin1":       ISO88591,
+	"latin-1":      ISO88591,
+	"ascii":        ASCII,
+	"us-ascii":     ASCII,
+}
+
+// ParseEncoding maps an encoding name or common alias such as "latin1" to its Encoding
+func ParseEncoding(name string) (Encoding, bool) {
+	encoding, exists := encodingAliases[strings.ToLower(name)]
+	return encoding, exists
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// windows1252High holds the characters Windows-1252 puts at 0x80-0x9F. The
+// five unassigned bytes decode to the C1 controls, as browsers do.
+var windows1252High = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// Decode turns content in the given encoding into a string. With Auto a
+// byte order mark selects UTF-8, UTF-16LE or UTF-16BE; a leading BOM is
+// never part of the result.
+func Decode(content []byte, encoding Encoding) string {
+	if encoding == Auto || encoding == "" {
+		switch {
+		case bytes.HasPrefix(content, bomUTF16LE):
+			encoding = UTF16LE
+		case bytes.HasPrefix(content, bomUTF16BE):
+			encoding = UTF16BE
+		default:
+			encoding = UTF8
+		}
+	}
+
+	switch encoding {
+	case UTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(content, bomUTF16LE), binary.LittleEndian)
+	case UTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(content, bomUTF16BE), binary.BigEndian)
+	case Windows1252, ISO88591:
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+			if encoding == Windows1252 && b >= 0x80 && b < 0xA0 {
+				runes[i] = windows1252High[b-0x80]
+			}
+		}
+		return string(runes)
+	}
+	return string(bytes.TrimPrefix(content, bomUTF8))
+}
+
+func decodeUTF16(content []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		units[i] = order.Uint16(content[2*i:])
+	}
+	text := string(utf16.Decode(units))
+	if len(content)%2 != 0 {
+		// A truncated final code unit
+		text += string(utf8.RuneError)
+	}
+	return text
+}
+
+// Encode turns text into bytes in the given encoding. Characters the
+// encoding cannot represent are passed to fallback, whose replacement is
+// used where it fits; anything still left over is written as '?'.
+func Encode(text string, encoding Encoding, fallback func(rune) string) []byte {
+	switch encoding {
+	case UTF8, Auto, "":
+		return []byte(text)
+	case UTF8BOM:
+		return append(append([]byte{}, bomUTF8...), text...)
+	case UTF16LE, UTF16BE:
+		var order binary.AppendByteOrder = binary.LittleEndian
+		out := append([]byte{}, bomUTF16LE...)
+		if encoding == UTF16BE {
+			order = binary.BigEndian
+			out = append([]byte{}, bomUTF16BE...)
+		}
+		for _, unit := range utf16.Encode([]rune(text)) {
+			out = order.AppendUint16(out, unit)
+		}
+		return out
+	}
+
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		if b, ok := encodeByte(r, encoding); ok {
+			out = append(out, b)
+			continue
+		}
+		replacement := "?"
+		if fallback != nil {
+			replacement = fallback(r)
+		}
+		for _, sub := range replacement {
+			b, ok := encodeByte(sub, encoding)
+			if !ok {
+				b = '?'
+			}
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// encodeByte returns the single byte that stands for r in a one-byte encoding
+func encodeByte(r rune, encoding Encoding) (byte, bool) {
+	switch encoding {
+	case ASCII:
+		return byte(r), r < utf8.RuneSelf
+	case ISO88591:
+		return byte(r), r < 0x100
+	case Windows1252:
+		if r < 0x80 || (r >= 0xA0 && r < 0x100) {
+			return byte(r), true
+		}
+		for i, high := range windows1252High {
+			if high == r {
+				return byte(0x80 + i), true
+			}
+		}
+	}
+	return 0, false
+}
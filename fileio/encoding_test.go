@@ -0,0 +1,85 @@
+package fileio
+
+import (
+	"bytes"
+	"itinerary-prettifier/formatter"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		encoding Encoding
+		want     string
+	}{
+		{"plain UTF-8", []byte("Café"), Auto, "Café"},
+		{"UTF-8 BOM", []byte("\xEF\xBB\xBFCafé"), Auto, "Café"},
+		{"UTF-8 BOM, named", []byte("\xEF\xBB\xBFCafé"), UTF8, "Café"},
+		{"UTF-16LE BOM", []byte{0xFF, 0xFE, 'H', 0, 'i', 0, 0xAC, 0x20}, Auto, "Hi€"},
+		{"UTF-16BE BOM", []byte{0xFE, 0xFF, 0, 'H', 0, 'i', 0x20, 0xAC}, Auto, "Hi€"},
+		{"UTF-16LE without BOM", []byte{'H', 0, 'i', 0}, UTF16LE, "Hi"},
+		{"UTF-16 surrogate pair", []byte{0xFF, 0xFE, 0x3D, 0xD8, 0x00, 0xDE}, Auto, "\U0001F600"},
+		{"UTF-16 truncated", []byte{0xFF, 0xFE, 'H', 0, 'i'}, Auto, "H�"},
+		{"Windows-1252 high range", []byte{0x80, 0x93, 'x', 0x94, 0x96, 0x9F}, Windows1252, "€“x”–Ÿ"},
+		{"Windows-1252 unassigned bytes", []byte{0x81, 0x8D, 0x8F, 0x90, 0x9D}, Windows1252, "\u0081\u008D\u008F\u0090\u009D"},
+		{"Windows-1252 Latin-1 range", []byte{'Z', 0xFC, 'r', 'i', 'c', 'h'}, Windows1252, "Zürich"},
+		{"ISO-8859-1 C1 controls", []byte{0x80, 0x93, 0xE9}, ISO88591, "\u0080\u0093é"},
+	}
+	for _, test := range tests {
+		if got := Decode(test.content, test.encoding); got != test.want {
+			t.Errorf("%s: Decode(% X, %s) = %q, want %q", test.name, test.content, test.encoding, got, test.want)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		encoding Encoding
+		fallback func(rune) string
+		want     []byte
+	}{
+		{"UTF-8", "Café", UTF8, nil, []byte("Café")},
+		{"UTF-8 BOM", "Café", UTF8BOM, nil, []byte("\xEF\xBB\xBFCafé")},
+		{"UTF-16LE", "Hi€", UTF16LE, nil, []byte{0xFF, 0xFE, 'H', 0, 'i', 0, 0xAC, 0x20}},
+		{"UTF-16BE", "Hi€", UTF16BE, nil, []byte{0xFE, 0xFF, 0, 'H', 0, 'i', 0x20, 0xAC}},
+		{"UTF-16 surrogate pair", "\U0001F600", UTF16LE, nil, []byte{0xFF, 0xFE, 0x3D, 0xD8, 0x00, 0xDE}},
+		{"Windows-1252 high range", "€“é”Ÿ", Windows1252, nil, []byte{0x80, 0x93, 0xE9, 0x94, 0x9F}},
+		{"Windows-1252 without fallback", "漢", Windows1252, nil, []byte("?")},
+		{"ISO-8859-1", "Zürich €", ISO88591, nil, []byte{'Z', 0xFC, 'r', 'i', 'c', 'h', ' ', '?'}},
+		{"ISO-8859-1 transliterated", "Zürich €", ISO88591, formatter.Transliterate, []byte{'Z', 0xFC, 'r', 'i', 'c', 'h', ' ', 'E', 'U', 'R'}},
+		{"ASCII transliterated", "Zürich – “LHR”…", ASCII, formatter.Transliterate, []byte(`Zurich - "LHR"...`)},
+		{"ASCII, Cyrillic transliterated", "Шереметьево", ASCII, formatter.Transliterate, []byte("Sheremetevo")},
+		{"fallback the encoding cannot hold either", "ő", ISO88591, func(rune) string { return "€x" }, []byte("?x")},
+	}
+	for _, test := range tests {
+		if got := Encode(test.text, test.encoding, test.fallback); !bytes.Equal(got, test.want) {
+			t.Errorf("%s: Encode(%q, %s) = % X, want % X", test.name, test.text, test.encoding, got, test.want)
+		}
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	text := "Zürich “Kloten” – 12 €\n"
+	for _, encoding := range []Encoding{UTF8, UTF8BOM, UTF16LE, UTF16BE, Windows1252} {
+		if got := Decode(Encode(text, encoding, nil), Auto); encoding != Windows1252 && got != text {
+			t.Errorf("%s: round trip = %q, want %q", encoding, got, text)
+		}
+		if got := Decode(Encode(text, encoding, nil), encoding); got != text {
+			t.Errorf("%s: round trip naming the encoding = %q, want %q", encoding, got, text)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for name, want := range map[string]Encoding{"UTF8": UTF8, "latin1": ISO88591, "cp1252": Windows1252, "US-ASCII": ASCII} {
+		if got, ok := ParseEncoding(name); !ok || got != want {
+			t.Errorf("ParseEncoding(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+	if _, ok := ParseEncoding("ebcdic"); ok {
+		t.Error(`ParseEncoding("ebcdic") accepted an unknown encoding`)
+	}
+}
@@ -10,10 +10,17 @@ type Reader interface {
 	ReadFile(path string) (string, error)
 }
 
-type FileReader struct{}
+type FileReader struct {
+	encoding Encoding
+}
 
 func NewFileReader() *FileReader {
-	return &FileReader{}
+	return &FileReader{encoding: Auto}
+}
+
+// SetEncoding sets how input bytes are decoded; Auto follows a byte order mark
+func (r *FileReader) SetEncoding(encoding Encoding) {
+	r.encoding = encoding
 }
 
 func (r *FileReader) ReadFile(path string) (string, error) {
@@ -21,7 +28,7 @@ func (r *FileReader) ReadFile(path string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("input not found: %w", err)
 	}
-	return string(content), nil
+	return Decode(content, r.encoding), nil
 }
 
 // Writer handles file writing operations
@@ -29,14 +36,24 @@ type Writer interface {
 	WriteFile(path string, content string) error
 }
 
-type FileWriter struct{}
+type FileWriter struct {
+	encoding Encoding
+	fallback func(rune) string
+}
 
 func NewFileWriter() *FileWriter {
-	return &FileWriter{}
+	return &FileWriter{encoding: UTF8}
+}
+
+// SetEncoding sets the output encoding. fallback supplies a stand-in, such as
+// a transliteration, for characters the encoding cannot represent.
+func (w *FileWriter) SetEncoding(encoding Encoding, fallback func(rune) string) {
+	w.encoding = encoding
+	w.fallback = fallback
 }
 
 func (w *FileWriter) WriteFile(path string, content string) error {
-	return os.WriteFile(path, []byte(content), 0644)
+	return os.WriteFile(path, Encode(content, w.encoding, w.fallback), 0644)
 }
 
 // Checker handles file existence checks
@@ -2,48 +2,90 @@ package formatter
 
 import (
 	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
 	"regexp"
 	"strings"
 )
 
-type AirportCodeReplacer struct{}
+// AirportCodeReplacer renders #ABC, ##ABCD, *#ABC and *##ABCD codes through
+// one template for each form
+type AirportCodeReplacer struct {
+	iataTemplate *AirportTemplate
+	icaoTemplate *AirportTemplate
+	cityTemplate *AirportTemplate // used for both *# and *## codes
+}
 
-func NewAirportFormatter() *AirportCodeReplacer {
-	return &AirportCodeReplacer{}
+func NewAirportFormatter(options types.FormatOptions) *AirportCodeReplacer {
+	return &AirportCodeReplacer{
+		iataTemplate: airportTemplateOrDefault(options.IATATemplate, DefaultIATATemplate),
+		icaoTemplate: airportTemplateOrDefault(options.ICAOTemplate, DefaultICAOTemplate),
+		cityTemplate: airportTemplateOrDefault(options.CityTemplate, DefaultCityTemplate),
+	}
 }
 
+// airportTemplateOrDefault parses pattern, using the fallback when the pattern
+// is empty or does not parse
+func airportTemplateOrDefault(pattern string, fallback string) *AirportTemplate {
+	template, err := ParseAirportTemplate(pattern)
+	if err != nil || template == nil {
+		template, _ = ParseAirportTemplate(fallback)
+	}
+	return template
+}
+
+// airportCodeRe matches a code at the start or end of a line or followed by
+// whitespace or closing punctuation, typographic quotes, ellipses and the
+// non-breaking spaces French quotes are set with included. The delimiter is
+// matched but not replaced, so a code before a line break or tab is found like
+// any other.
+var airportCodeRe = regexp.MustCompile(`(\*?(?:##[A-Z]{3,4}|#[A-Z]{3}))(?:[\s\x{00A0}\x{202F}.,;!?)"'”’“‘»›」』…]|$)`)
+
+// leftoverTokenRes match the tokens whose codes belong to the token: by the
+// time codes are replaced, any still in the text were left unchanged
+var leftoverTokenRes = []*regexp.Regexp{distancePattern, timeRe, fieldTokenRe}
+
 func (f *AirportCodeReplacer) ReplaceAirportCodes(text string, service airports.Service) string {
-	// Less restrictive patterns - allow codes at start/end of line or surrounded by whitespace/punctuation
-	patterns := []string{
-		`(\*?##[A-Z]{3,4})(?:\s|$|\.|,|;|!|\?|\)|"|')`, // *##ABCD or ##ABCD
-		`(\*?#[A-Z]{3})(?:\s|$|\.|,|;|!|\?|\)|"|')`,    // *#ABC or #ABC
+	var leftovers [][]int
+	for _, re := range leftoverTokenRes {
+		leftovers = append(leftovers, re.FindAllStringIndex(text, -1)...)
 	}
-	
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		text = re.ReplaceAllStringFunc(text, func(match string) string {
-			// Extract the code part (remove trailing delimiter)
-			code := strings.TrimRight(match, " .,;!?)\"'")
-			
-			var replacement string
-			if strings.HasPrefix(code, "*") {
-				replacement = service.GetCityName(code)
-			} else {
-				replacement = service.GetAirportName(code)
-			}
-			
+	var out strings.Builder
+	last := 0
+	for _, match := range airportCodeRe.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[2], match[3]
+		code := text[start:end]
+		if insideSpan(leftovers, start) {
+			continue
+		}
+		airport, exists := service.GetAirport(code)
+		if !exists {
 			// Only replace if we found the airport
-			if replacement != code {
-				// Restore the trailing delimiter
-				if len(match) > len(code) {
-					return replacement + match[len(code):]
-				}
-				return replacement
-			}
-			
-			return match
-		})
+			continue
+		}
+		out.WriteString(text[last:start])
+		out.WriteString(f.templateFor(code).Render(airport))
+		last = end
 	}
-	
-	return text
-}
\ No newline at end of file
+	out.WriteString(text[last:])
+	return out.String()
+}
+
+func (f *AirportCodeReplacer) templateFor(code string) *AirportTemplate {
+	switch {
+	case strings.HasPrefix(code, "*"):
+		return f.cityTemplate
+	case strings.HasPrefix(code, "##"):
+		return f.icaoTemplate
+	}
+	return f.iataTemplate
+}
+
+// insideSpan reports whether offset falls within one of spans
+func insideSpan(spans [][]int, offset int) bool {
+	for _, span := range spans {
+		if offset >= span[0] && offset < span[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"fmt"
+	"itinerary-prettifier/airports"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ChronologyChecker reports timestamps in an itinerary that are out of order
+type ChronologyChecker interface {
+	CheckChronology(text string, airportService airports.Service) []string
+}
+
+type ChronologyLinter struct{}
+
+func NewChronologyLinter() *ChronologyLinter {
+	return &ChronologyLinter{}
+}
+
+// lintTokenRe finds T12/T24/T tokens (with an optional @airport) and D tokens
+var lintTokenRe = regexp.MustCompile(`(?:T(?:12|24)|\bT)\(([^)@]+)(?:@\s*(##?[A-Z0-9]{3,4})\s*)?\)|\bD\(([^)]+)\)`)
+
+// segment spans the first to the last time token of one line
+type segment struct {
+	line       int
+	start, end time.Time
+}
+
+// CheckChronology gathers the T12/T24/D timestamps of text in document order
+// and warns when a line's first time is earlier than the previous line's last
+// time, when a D date is earlier than the D date before it, and when a
+// time written for an airport falls in a DST gap or overlap of its zone or
+// has an offset the zone does not have then.
+// Tokens that do not parse are skipped; the formatter reports those.
+func (c *ChronologyLinter) CheckChronology(text string, airportService airports.Service) []string {
+	var warnings []string
+	var previous *segment
+	var previousDate time.Time
+	var previousDateLine int
+
+	for i, line := range strings.Split(text, "\n") {
+		lineNo := i + 1
+		var current *segment
+
+		for _, match := range lintTokenRe.FindAllStringSubmatch(line, -1) {
+			if match[3] != "" {
+				date, ok := parseLintDate(match[3])
+				if !ok {
+					continue
+				}
+				if previousDateLine > 0 && date.Before(previousDate) {
+					warnings = append(warnings, fmt.Sprintf("line %d: date %s goes back before %s on line %d",
+						lineNo, date.Format("2006-01-02"), previousDate.Format("2006-01-02"), previousDateLine))
+				}
+				previousDate, previousDateLine = date, lineNo
+				continue
+			}
+
+			value, err := ParseISO8601(strings.TrimSpace(match[1]))
+			if err != nil || !value.HasTime || !value.HasOffset {
+				continue
+			}
+			if match[2] != "" {
+				if warning := c.checkZoneTransition(value, match[2], airportService); warning != "" {
+					warnings = append(warnings, fmt.Sprintf("line %d: %s", lineNo, warning))
+				}
+			}
+
+			if current == nil {
+				current = &segment{line: lineNo, start: value.Time, end: value.Time}
+			}
+			current.end = value.Time
+		}
+
+		if current == nil {
+			continue
+		}
+		if previous != nil && current.start.Before(previous.end) {
+			warnings = append(warnings, fmt.Sprintf("line %d: segment starts at %s, before the segment on line %d ends at %s",
+				lineNo, formatLintTime(current.start), previous.line, formatLintTime(previous.end)))
+		}
+		previous = current
+	}
+	return warnings
+}
+
+// checkZoneTransition reads the wall-clock time as written against the
+// airport's zone. It reports a time the zone skips (a gap), a time the zone
+// shows twice (an overlap, naming the reading the offset picks) and an offset
+// the zone does not have at that instant. Times written with Z are instants
+// in UTC, not wall-clock times, and are never flagged.
+func (c *ChronologyLinter) checkZoneTransition(value ISOTimestamp, airportCode string, airportService airports.Service) string {
+	location, ok := airportService.GetTimeZone(airportCode)
+	if !ok || value.Zulu {
+		return ""
+	}
+	t := value.Time
+	_, written := t.Zone()
+
+	y, mo, d := t.Date()
+	h, mi, s := t.Clock()
+	local := time.Date(y, mo, d, h, mi, s, t.Nanosecond(), location)
+	if local.Hour() != h || local.Minute() != mi {
+		return fmt.Sprintf("%s does not exist in %s; clocks skip it", formatWallClock(t), location)
+	}
+
+	// The same wall-clock time read with the offsets in force a few hours
+	// either side; two different instants mean the time is ambiguous
+	_, before := local.Add(-3 * time.Hour).Zone()
+	_, after := local.Add(3 * time.Hour).Zone()
+	if before != after {
+		wall := time.Date(y, mo, d, h, mi, s, t.Nanosecond(), time.UTC)
+		early := wall.Add(-time.Duration(before) * time.Second).In(location)
+		late := wall.Add(-time.Duration(after) * time.Second).In(location)
+		if !early.Equal(late) && early.Hour() == h && late.Hour() == h {
+			warning := fmt.Sprintf("%s occurs twice in %s", t.Format("15:04"), location)
+			for _, reading := range []time.Time{early, late} {
+				if name, offset := reading.Zone(); offset == written {
+					return fmt.Sprintf("%s; the offset picks the %s one", warning, name)
+				}
+			}
+			return fmt.Sprintf("%s; neither reading has the offset %s", warning, formatUTCOffset(t))
+		}
+	}
+
+	if _, actual := t.In(location).Zone(); actual != written {
+		return fmt.Sprintf("%s (%s) does not match %s, which is at %s then",
+			formatWallClock(t), formatUTCOffset(t), location, formatUTCOffset(t.In(location)))
+	}
+	return ""
+}
+
+func parseLintDate(isoStr string) (time.Time, bool) {
+	value, err := ParseISO8601(strings.TrimSpace(isoStr))
+	if err != nil || (value.HasTime && !value.HasOffset) {
+		return time.Time{}, false
+	}
+	y, m, d := value.Time.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), true
+}
+
+func formatLintTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04 (-07:00)")
+}
+
+func formatWallClock(t time.Time) string {
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,51 @@
+package formatter
+
+import (
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+	"reflect"
+	"testing"
+)
+
+func TestCheckChronologyZoneOffsets(t *testing.T) {
+	service := airports.NewAirportService(airports.NewAirportRepository(map[string]types.Airport{
+		"#LHR": {Name: "London Heathrow Airport", IATA: "LHR", TimeZone: "Europe/London"},
+		"#JFK": {Name: "John F Kennedy International Airport", IATA: "JFK", TimeZone: "America/New_York"},
+	}))
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		// Z marks an instant in UTC, never a wall-clock time
+		{"T24(2025-03-09T02:30:00Z@#JFK)", nil},
+		{"T24(2025-03-30T01:30:00Z@#LHR)", nil},
+		{"T24(2025-03-09T03:30:00-04:00@#JFK)", nil},
+		{"T24(2025-01-15T09:15:00+00:00@#LHR)", nil},
+		{"T24(2025-03-30T01:30:00+00:00@#LHR)", []string{
+			"line 1: 2025-03-30 01:30 does not exist in Europe/London; clocks skip it",
+		}},
+		{"T24(2025-03-09T02:30:00-05:00@#JFK)", []string{
+			"line 1: 2025-03-09 02:30 does not exist in America/New_York; clocks skip it",
+		}},
+		{"T24(2025-11-02T01:30:00-04:00@#JFK)", []string{
+			"line 1: 01:30 occurs twice in America/New_York; the offset picks the EDT one",
+		}},
+		{"T24(2025-11-02T01:30:00-05:00@#JFK)", []string{
+			"line 1: 01:30 occurs twice in America/New_York; the offset picks the EST one",
+		}},
+		{"T24(2025-11-02T01:30:00-06:00@#JFK)", []string{
+			"line 1: 01:30 occurs twice in America/New_York; neither reading has the offset -06:00",
+		}},
+		{"T24(2025-07-05T09:15:00+00:00@#LHR)", []string{
+			"line 1: 2025-07-05 09:15 (+00:00) does not match Europe/London, which is at +01:00 then",
+		}},
+		{"T24(2025-07-05T09:15:00+02:00@#LHR)", []string{
+			"line 1: 2025-07-05 09:15 (+02:00) does not match Europe/London, which is at +01:00 then",
+		}},
+	}
+	for _, test := range tests {
+		if got := NewChronologyLinter().CheckChronology(test.input, service); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("CheckChronology(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
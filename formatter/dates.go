@@ -1,37 +1,75 @@
 package formatter
 
 import (
+    "errors"
     "fmt"
+    "itinerary-prettifier/airports"
+    "itinerary-prettifier/types"
     "regexp"
-    "strconv"
     "strings"
     "time"
 )
 
 // DateFormatter handles date and time formatting
 type DateFormatter interface {
-    ReplaceTimesThenDates(text string) string
+    ReplaceTimesThenDates(text string, airportService airports.Service) string
     FormatTimeToken(token string, format string) string
     FormatDateToken(token string) string
+    Rejections() []error
 }
 
-type DateTimeProcessor struct{}
+type DateTimeProcessor struct {
+    locale         *Locale
+    dateLayout     *Layout // nil keeps the locale's date format
+    time12Layout   *Layout // nil keeps hh:mmAM (+hh:mm)
+    time24Layout   *Layout // nil keeps HH:MM (+hh:mm)
+    dateTimeLayout *Layout // nil joins the date and the locale's usual time
+    minLayover     time.Duration
+    dayMarkers     bool
+    clock          func() time.Time // reference time for REL tokens
+    rejections     []error
+}
 
-func NewDateFormatter() *DateTimeProcessor {
-    return &DateTimeProcessor{}
+func NewDateFormatter(options types.FormatOptions) *DateTimeProcessor {
+    locale, exists := LookupLocale(options.Locale)
+    if !exists {
+        locale, _ = LookupLocale(DefaultLocale)
+    }
+    // Layouts are checked by the config validator, so errors here only leave the default in place
+    dateLayout, _ := ParseLayout(options.DateLayout)
+    time12Layout, _ := ParseLayout(options.Time12Layout)
+    time24Layout, _ := ParseLayout(options.Time24Layout)
+    dateTimeLayout, _ := ParseLayout(options.DateTimeLayout)
+    clock := options.Clock
+    if clock == nil {
+        clock = time.Now
+    }
+    return &DateTimeProcessor{
+        locale:         locale,
+        dateLayout:     dateLayout,
+        time12Layout:   time12Layout,
+        time24Layout:   time24Layout,
+        dateTimeLayout: dateTimeLayout,
+        minLayover:     options.MinLayover,
+        dayMarkers:     options.DayMarkers,
+        clock:          clock,
+    }
 }
 
-func (f *DateTimeProcessor) ReplaceTimesThenDates(text string) string {
-    // Process T12 and T24 first
-    t12Re := regexp.MustCompile(`T12\(([^)]+)\)`)
-    text = t12Re.ReplaceAllStringFunc(text, func(match string) string {
-        return f.FormatTimeToken(match, "12h")
-    })
-    
-    t24Re := regexp.MustCompile(`T24\(([^)]+)\)`)
-    text = t24Re.ReplaceAllStringFunc(text, func(match string) string {
-        return f.FormatTimeToken(match, "24h")
-    })
+func (f *DateTimeProcessor) ReplaceTimesThenDates(text string, airportService airports.Service) string {
+    // Multi-value and derived tokens go before the single-field T and D tokens
+    text = f.replaceDurations(text)
+    text = f.replaceDateRanges(text)
+    text = f.replaceDateTimes(text)
+    text = f.replaceWeekdays(text)
+    text = f.replaceRelativeDays(text)
+
+    // Process T12 and T24 first, a line at a time so arrivals can be compared with departures
+    lines := strings.Split(text, "\n")
+    for i, line := range lines {
+        lines[i] = f.replaceTimesInLine(line, airportService)
+    }
+    text = strings.Join(lines, "\n")
     
     // Process D(...) last
     dRe := regexp.MustCompile(`D\(([^)]+)\)`)
@@ -42,6 +80,82 @@ func (f *DateTimeProcessor) ReplaceTimesThenDates(text string) string {
     return text
 }
 
+// timeRe matches T12/T24 tokens and plain T tokens, which use the locale's
+// usual clock. An airport code after '@' asks for the time in that airport's
+// zone, e.g. T24(2025-03-05T08:15:00Z@#LHR)
+var timeRe = regexp.MustCompile(`(?:T(12|24)|\bT)\(([^)@]+)(?:@\s*(##?[A-Z0-9]{3,4})\s*)?\)`)
+
+// replaceTimesInLine renders the time tokens of one line. The first token is
+// taken as the departure; later tokens on a later calendar day get a "+1",
+// "+2", ... marker the way airline schedules show overnight arrivals.
+func (f *DateTimeProcessor) replaceTimesInLine(line string, airportService airports.Service) string {
+    var departure time.Time
+    seenDeparture := false
+    return timeRe.ReplaceAllStringFunc(line, func(match string) string {
+        parts := timeRe.FindStringSubmatch(match)
+        clock := parts[1]
+        if clock == "" {
+            clock = "12"
+            if f.locale.Clock24 {
+                clock = "24"
+            }
+        }
+        t, rendered, err := f.renderTime(clock, strings.TrimSpace(parts[2]), parts[3], airportService)
+        if err != nil {
+            f.reject(match, err)
+            return match
+        }
+        if !seenDeparture {
+            departure, seenDeparture = t, true
+            return rendered
+        }
+        if days := calendarDaysBetween(departure, t); f.dayMarkers && days > 0 && days <= maxDayMarker {
+            rendered += fmt.Sprintf(" +%d", days)
+        }
+        return rendered
+    })
+}
+
+// maxDayMarker bounds the day markers; tokens further apart than any flight
+// are unrelated times rather than a departure and its arrival
+const maxDayMarker = 3
+
+// renderTime formats one time token and returns the instant in the zone it
+// was rendered in. Tokens naming an airport whose zone is unknown fail.
+func (f *DateTimeProcessor) renderTime(clock string, isoStr string, airportCode string, airportService airports.Service) (time.Time, string, error) {
+    t, err := f.parseTime(isoStr)
+    if err != nil {
+        return time.Time{}, "", err
+    }
+
+    offsetStr := f.formatOffset(t)
+    if airportCode != "" {
+        location, ok := airportService.GetTimeZone(airportCode)
+        if !ok {
+            return time.Time{}, "", fmt.Errorf("no time zone known for %s", airportCode)
+        }
+        t = t.In(location)
+        offsetStr = f.formatOffset(t)
+        // Zones without a letter abbreviation report their offset instead, e.g. "+03"
+        if abbr, _ := t.Zone(); !strings.HasPrefix(abbr, "+") && !strings.HasPrefix(abbr, "-") {
+            offsetStr = abbr + " " + offsetStr
+        }
+    }
+
+    if clock == "12" {
+        return t, f.format12HourTime(t, offsetStr), nil
+    }
+    return t, f.format24HourTime(t, offsetStr), nil
+}
+
+// calendarDaysBetween counts calendar days from a's date to b's date, each
+// taken in its own zone
+func calendarDaysBetween(a, b time.Time) int {
+    dayA := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+    dayB := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+    return int(dayB.Sub(dayA).Hours() / 24)
+}
+
 func (f *DateTimeProcessor) FormatTimeToken(token string, format string) string {
     // Extract the ISO string inside parentheses
     var isoStr string
@@ -61,19 +175,15 @@ func (f *DateTimeProcessor) FormatTimeToken(token string, format string) string
         isoStr = strings.TrimSpace(match[1])
     }
     
-    // Validate the format first - offset must be in format ±HH:MM
-    if !f.isValidTimeFormat(isoStr) {
-        return token
-    }
-    
-    // Parse the ISO time
+    // Parse the ISO time; an offset is required
     t, err := f.parseTime(isoStr)
     if err != nil {
+        f.reject(token, err)
         return token
     }
     
     // Format the offset
-    offsetStr := f.formatOffset(isoStr, t)
+    offsetStr := f.formatOffset(t)
     
     // Format the time according to specification
     if format == "12h" {
@@ -93,162 +203,85 @@ func (f *DateTimeProcessor) FormatDateToken(token string) string {
     isoStr := strings.TrimSpace(match[1])
     t, err := f.parseDate(isoStr)
     if err != nil {
+        f.reject(token, err)
         return token
     }
     
-    // Format as DD-Mmm-YYYY
-    months := []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", 
-        "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
-    
-    day := t.Day()
-    month := months[t.Month()-1]
-    year := t.Year()
-    
-    return fmt.Sprintf("%02d %s %d", day, month, year)
+    if f.dateLayout != nil {
+        return f.dateLayout.Format(t, f.locale)
+    }
+    // Format as DD Mmm YYYY, or the locale's own field order
+    return f.locale.FormatDate(t)
 }
 
+// parseTime accepts an ISO 8601 date-time that carries a UTC offset
 func (f *DateTimeProcessor) parseTime(isoStr string) (time.Time, error) {
-    // Replace any non-standard minus characters
-    isoStr = strings.ReplaceAll(isoStr, "−", "-")
-    
-    // Try different time formats
-    formats := []string{
-        "2006-01-02T15:04:05-07:00",
-        "2006-01-02T15:04-07:00",
-        "2006-01-02T15:04:05Z07:00",
-        "2006-01-02T15:04Z07:00",
-        "2006-01-02T15:04:05Z",
-        "2006-01-02T15:04Z",
+    value, err := ParseISO8601(isoStr)
+    if err != nil {
+        return time.Time{}, err
     }
-    
-    for _, format := range formats {
-        t, err := time.Parse(format, isoStr)
-        if err == nil {
-            return t, nil
-        }
+    if !value.HasTime {
+        return time.Time{}, &ISOError{Input: isoStr, Reason: "missing time of day"}
     }
-    
-    return time.Time{}, fmt.Errorf("unable to parse time")
+    if !value.HasOffset {
+        return time.Time{}, &ISOError{Input: isoStr, Reason: "missing UTC offset"}
+    }
+    return value.Time, nil
 }
 
+// parseDate accepts an ISO 8601 date, or a date-time that carries a UTC offset
 func (f *DateTimeProcessor) parseDate(isoStr string) (time.Time, error) {
-    // Replace any non-standard minus characters
-    isoStr = strings.ReplaceAll(isoStr, "−", "-")
-    
-    // Try different date formats
-    formats := []string{
-        "2006-01-02T15:04:05-07:00",
-        "2006-01-02T15:04-07:00", 
-        "2006-01-02T15:04:05Z",
-        "2006-01-02T15:04Z",
-        "2006-01-02",
+    value, err := ParseISO8601(isoStr)
+    if err != nil {
+        return time.Time{}, err
     }
-    
-    for _, format := range formats {
-        t, err := time.Parse(format, isoStr)
-        if err == nil {
-            return t, nil
-        }
+    if value.HasTime && !value.HasOffset {
+        return time.Time{}, &ISOError{Input: isoStr, Reason: "missing UTC offset"}
     }
-    
-    return time.Time{}, fmt.Errorf("unable to parse date")
+    return value.Time, nil
 }
 
-func (f *DateTimeProcessor) isValidTimeFormat(isoStr string) bool {
-    // Check for Zulu time
-    if strings.HasSuffix(strings.ToUpper(isoStr), "Z") {
-        return true
-    }
-    
-    // Find the offset part (should be at the end)
-    offsetIndex := strings.LastIndex(isoStr, "+")
-    if offsetIndex == -1 {
-        offsetIndex = strings.LastIndex(isoStr, "-")
-        // Make sure it's not the first character (which would be part of the date)
-        if offsetIndex <= 10 { // Date starts with YYYY-MM-DD
-            return false
-        }
-    }
-    
-    if offsetIndex == -1 {
-        return false
-    }
-    
-    offsetStr := isoStr[offsetIndex:]
-    
-    // Offset should be in format ±HH:MM
-    if len(offsetStr) != 6 {
-        return false
+// reject remembers why a token was left unchanged
+func (f *DateTimeProcessor) reject(token string, err error) {
+    var isoErr *ISOError
+    if errors.As(err, &isoErr) {
+        err = errors.New(isoErr.Reason)
     }
-    
-    // Check colon position
-    if offsetStr[3] != ':' {
-        return false
-    }
-    
-    // Check that hours and minutes are digits
-    hours := offsetStr[1:3]
-    minutes := offsetStr[4:6]
-    
-    if _, err := strconv.Atoi(hours); err != nil {
-        return false
-    }
-    if _, err := strconv.Atoi(minutes); err != nil {
-        return false
-    }
-    
-    // Validate hour range
-    hourNum, _ := strconv.Atoi(hours)
-    if hourNum < 0 || hourNum > 23 {
-        return false
-    }
-    
-    // Validate minute range  
-    minuteNum, _ := strconv.Atoi(minutes)
-    if minuteNum < 0 || minuteNum > 59 {
-        return false
-    }
-    
-    return true
+    f.rejections = append(f.rejections, fmt.Errorf("%s left unchanged: %w", token, err))
 }
 
-func (f *DateTimeProcessor) formatOffset(isoStr string, t time.Time) string {
-    _, offset := t.Zone()
-    offsetHours := offset / 3600
-    offsetMinutes := (offset % 3600) / 60
-    
-    // Handle Zulu time specifically
-    if strings.HasSuffix(strings.ToUpper(isoStr), "Z") {
-        return "(+00:00)"
-    }
-    
-    // Format with proper sign
-    sign := "+"
-    if offsetHours < 0 {
-        sign = "-"
-        offsetHours = -offsetHours
-    }
-    
-    return fmt.Sprintf("(%s%02d:%02d)", sign, offsetHours, offsetMinutes)
+// Rejections lists every token left unchanged so far, with the reason
+func (f *DateTimeProcessor) Rejections() []error {
+    return f.rejections
+}
+
+func (f *DateTimeProcessor) formatOffset(t time.Time) string {
+    return "(" + formatUTCOffset(t) + ")"
 }
 
 func (f *DateTimeProcessor) format12HourTime(t time.Time, offsetStr string) string {
-    // 12-hour format with AM/PM
+    if f.time12Layout != nil {
+        return f.time12Layout.Format(t, f.locale)
+    }
+    // 12-hour format with the locale's AM/PM markers
     hour := t.Hour()
-    ampm := "AM"
-    if hour >= 12 {
-        ampm = "PM"
-        if hour > 12 {
-            hour -= 12
-        }
+    ampm := f.locale.Meridiem(hour)
+    if hour > 12 {
+        hour -= 12
     }
     if hour == 0 {
         hour = 12
     }
+    if f.locale.MeridiemFirst {
+        return fmt.Sprintf("%s%02d:%02d %s", ampm, hour, t.Minute(), offsetStr)
+    }
     return fmt.Sprintf("%02d:%02d%s %s", hour, t.Minute(), ampm, offsetStr)
 }
 
 func (f *DateTimeProcessor) format24HourTime(t time.Time, offsetStr string) string {
+    if f.time24Layout != nil {
+        return f.time24Layout.Format(t, f.locale)
+    }
     // 24-hour format
     return fmt.Sprintf("%02d:%02d %s", t.Hour(), t.Minute(), offsetStr)
 }
\ No newline at end of file
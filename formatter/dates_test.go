@@ -0,0 +1,20 @@
+package formatter
+
+import (
+	"itinerary-prettifier/types"
+	"strings"
+	"testing"
+)
+
+func TestRejectedTimeTokensKeepTheirCode(t *testing.T) {
+	textFormatter := NewTextFormatter(types.FormatOptions{})
+	// The test airports have no time zone
+	got := textFormatter.Prettify("T24(2025-07-05T08:15:00Z@#LHR) at #LHR", newTestService())
+	if want := "T24(2025-07-05T08:15:00Z@#LHR) at London Heathrow Airport"; got != want {
+		t.Errorf("Prettify() = %q, want %q", got, want)
+	}
+	warnings := textFormatter.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "no time zone known for #LHR") {
+		t.Errorf("Warnings() = %q", warnings)
+	}
+}
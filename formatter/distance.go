@@ -0,0 +1,85 @@
+package formatter
+
+import (
+	"fmt"
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+	"math"
+	"regexp"
+)
+
+// DistanceFormatter renders great-circle distances between airports
+type DistanceFormatter interface {
+	ReplaceDistances(text string, airportService airports.Service) string
+	Rejections() []error
+}
+
+// Distance units accepted by NewDistanceFormatter
+const (
+	Kilometres    = "km"
+	Miles         = "mi"
+	NauticalMiles = "nm"
+)
+
+const earthRadiusKm = 6371.0088
+
+type DistanceProcessor struct {
+	unit       string
+	rejections []error
+}
+
+func NewDistanceFormatter(unit string) *DistanceProcessor {
+	if unit == "" {
+		unit = Kilometres
+	}
+	return &DistanceProcessor{unit: unit}
+}
+
+var distancePattern = regexp.MustCompile(`DIST\(\s*(##?[A-Z0-9]{3,4})\s*,\s*(##?[A-Z0-9]{3,4})\s*\)`)
+
+// ReplaceDistances turns DIST(#AAA,##BBBB) into a distance in the configured
+// unit. Tokens naming an airport without coordinates are left untouched.
+func (f *DistanceProcessor) ReplaceDistances(text string, service airports.Service) string {
+	return distancePattern.ReplaceAllStringFunc(text, func(match string) string {
+		codes := distancePattern.FindStringSubmatch(match)
+		from, ok := service.GetPosition(codes[1])
+		if !ok {
+			f.rejections = append(f.rejections, fmt.Errorf("%s left unchanged: no coordinates known for %s", match, codes[1]))
+			return match
+		}
+		to, ok := service.GetPosition(codes[2])
+		if !ok {
+			f.rejections = append(f.rejections, fmt.Errorf("%s left unchanged: no coordinates known for %s", match, codes[2]))
+			return match
+		}
+		return f.formatDistance(greatCircleKm(from, to))
+	})
+}
+
+// Rejections lists every distance token left unchanged so far, with the reason
+func (f *DistanceProcessor) Rejections() []error {
+	return f.rejections
+}
+
+func (f *DistanceProcessor) formatDistance(km float64) string {
+	switch f.unit {
+	case Miles:
+		return fmt.Sprintf("%.0f mi", km/1.609344)
+	case NauticalMiles:
+		return fmt.Sprintf("%.0f NM", km/1.852)
+	default:
+		return fmt.Sprintf("%.0f km", km)
+	}
+}
+
+// greatCircleKm applies the haversine formula on a spherical Earth
+func greatCircleKm(from, to types.Position) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
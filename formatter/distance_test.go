@@ -0,0 +1,18 @@
+package formatter
+
+import (
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func TestFailedDistanceTokensAreLeftUnchanged(t *testing.T) {
+	textFormatter := NewTextFormatter(types.FormatOptions{})
+	got := textFormatter.Prettify("DIST(#LHR,#XXX) from #LHR", newTestService())
+	if want := "DIST(#LHR,#XXX) from London Heathrow Airport"; got != want {
+		t.Errorf("Prettify() = %q, want %q", got, want)
+	}
+	warnings := textFormatter.Warnings()
+	if len(warnings) != 1 || warnings[0] != "DIST(#LHR,#XXX) left unchanged: no coordinates known for #XXX" {
+		t.Errorf("Warnings() = %q", warnings)
+	}
+}
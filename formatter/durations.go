@@ -0,0 +1,53 @@
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// durationRe matches DUR(start,end) and LAY(start,end) tokens
+var durationRe = regexp.MustCompile(`\b(DUR|LAY)\(([^,()]+),([^,()]+)\)`)
+
+// replaceDurations renders the elapsed time between two ISO timestamps.
+// LAY tokens additionally flag connections shorter than the minimum layover.
+// Tokens with an unparsable timestamp or an end before the start are left as is.
+func (f *DateTimeProcessor) replaceDurations(text string) string {
+	return durationRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := durationRe.FindStringSubmatch(match)
+		start, err := f.parseTime(strings.TrimSpace(parts[2]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+		end, err := f.parseTime(strings.TrimSpace(parts[3]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+
+		// time.Time subtraction compares instants, so differing offsets cancel out
+		elapsed := end.Sub(start)
+		if elapsed < 0 {
+			f.reject(match, errors.New("end is before start"))
+			return match
+		}
+
+		rendered := formatDuration(elapsed)
+		if parts[1] == "LAY" && elapsed < f.minLayover {
+			rendered += " (short connection)"
+		}
+		return rendered
+	})
+}
+
+// formatDuration renders whole minutes as "13h 15m", or "45m" under an hour
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
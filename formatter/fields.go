@@ -0,0 +1,59 @@
+package formatter
+
+import (
+	"fmt"
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+	"regexp"
+	"strings"
+)
+
+// FieldFormatter replaces field tokens such as {#LHR.iso_country} with one
+// column of the airport's lookup record
+type FieldFormatter interface {
+	ReplaceFieldTokens(text string, airportService airports.Service) string
+	Rejections() []error
+}
+
+type FieldTokenReplacer struct {
+	rejections []error
+}
+
+func NewFieldFormatter() *FieldTokenReplacer {
+	return &FieldTokenReplacer{}
+}
+
+// fieldTokenRe matches {#ABC.column} and {##ABCD.column}
+var fieldTokenRe = regexp.MustCompile(`\{(##?[A-Z0-9]{3,4})\.([A-Za-z0-9_]+)\}`)
+
+func (f *FieldTokenReplacer) ReplaceFieldTokens(text string, airportService airports.Service) string {
+	return fieldTokenRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := fieldTokenRe.FindStringSubmatch(match)
+		airport, exists := airportService.GetAirport(parts[1])
+		if !exists {
+			f.rejections = append(f.rejections, fmt.Errorf("%s left unchanged: unknown airport %s", match, parts[1]))
+			return match
+		}
+		value, exists := airportField(airport, strings.ToLower(parts[2]))
+		if !exists {
+			f.rejections = append(f.rejections, fmt.Errorf("%s left unchanged: no column %s", match, parts[2]))
+			return match
+		}
+		return value
+	})
+}
+
+// Rejections lists every field token left unchanged so far, with the reason
+func (f *FieldTokenReplacer) Rejections() []error {
+	return f.rejections
+}
+
+// airportField reads a named field of the record, or one of the extra columns
+// kept from the lookup file
+func airportField(airport types.Airport, name string) (string, bool) {
+	if field, exists := airportTemplateFields[name]; exists {
+		return field(airport), true
+	}
+	value, exists := airport.Extra[name]
+	return value, exists
+}
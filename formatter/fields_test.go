@@ -0,0 +1,21 @@
+package formatter
+
+import (
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func TestFieldTokensReadRawColumns(t *testing.T) {
+	service := airports.NewAirportService(airports.NewAirportRepository(map[string]types.Airport{
+		"#LHR": {
+			Name: "London Heathrow Airport", ISOCountry: "GB", IATA: "LHR", TimeZone: "Europe/London",
+			Extra: map[string]string{"latitude_deg": "51.4706", "tz": "Europe/London"},
+		},
+	}))
+	textFormatter := NewTextFormatter(types.FormatOptions{})
+	got := textFormatter.Prettify("{#LHR.latitude_deg} {#LHR.TZ} {#LHR.time_zone} {#LHR.country} {#LHR.nope}", service)
+	if want := "51.4706 Europe/London Europe/London GB {#LHR.nope}"; got != want {
+		t.Errorf("Prettify() = %q, want %q", got, want)
+	}
+}
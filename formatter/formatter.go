@@ -1,6 +1,9 @@
 package formatter
 
-import "itinerary-prettifier/airports"
+import (
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+)
 
 // Formatter orchestrates all text formatting operations
 type Formatter interface {
@@ -15,23 +18,120 @@ type AirportFormatter interface {
 type TextFormatter struct {
 	whitespaceFormatter WhitespaceFormatter
 	airportFormatter    AirportFormatter
+	fieldFormatter      FieldFormatter
 	dateFormatter       DateFormatter
+	distanceFormatter   DistanceFormatter
+	chronologyChecker   ChronologyChecker   // nil unless chronology checks are enabled
+	structureProtector  StructureProtector  // nil unless the input is markdown
+	typographyFormatter TypographyFormatter // nil unless smart typography is enabled
+	lineWrapper         LineWrapper         // nil unless a wrap width is set
+	asciiMode           string              // which text is transliterated to ASCII
+	lineEnding          LineEnding          // empty keeps the input's own line endings
+	lintWarnings        []string
 }
 
-func NewTextFormatter() *TextFormatter {
-	return &TextFormatter{
-		whitespaceFormatter: NewWhitespaceFormatter(),
-		airportFormatter:    NewAirportFormatter(),
-		dateFormatter:       NewDateFormatter(),
+func NewTextFormatter(options types.FormatOptions) *TextFormatter {
+	profile, exists := LookupWhitespaceProfile(options.WhitespaceProfile)
+	if !exists {
+		profile, _ = LookupWhitespaceProfile(DefaultWhitespaceProfile)
 	}
+	textFormatter := &TextFormatter{
+		whitespaceFormatter: NewWhitespaceFormatter(profile),
+		airportFormatter:    NewAirportFormatter(options),
+		fieldFormatter:      NewFieldFormatter(),
+		dateFormatter:       NewDateFormatter(options),
+		distanceFormatter:   NewDistanceFormatter(options.DistanceUnit),
+		asciiMode:           options.ASCII,
+	}
+	if ending, exists := ParseLineEnding(options.LineEnding); exists {
+		textFormatter.lineEnding = ending
+	}
+	if options.CheckChronology {
+		textFormatter.chronologyChecker = NewChronologyLinter()
+	}
+	if options.SmartTypography {
+		locale, exists := LookupLocale(options.Locale)
+		if !exists {
+			locale, _ = LookupLocale(DefaultLocale)
+		}
+		textFormatter.typographyFormatter = NewTypographyFormatter(locale)
+	}
+	if options.WrapWidth > 0 {
+		textFormatter.lineWrapper = NewLineWrapper(options.WrapWidth, options.Markdown)
+	}
+	if options.Markdown {
+		textFormatter.structureProtector = NewMarkdownProtector()
+	}
+	return textFormatter
+}
+
+// Warnings lists chronology findings and explains each token left unchanged
+func (f *TextFormatter) Warnings() []string {
+	warnings := append([]string{}, f.lintWarnings...)
+	for _, err := range f.distanceFormatter.Rejections() {
+		warnings = append(warnings, err.Error())
+	}
+	for _, err := range f.dateFormatter.Rejections() {
+		warnings = append(warnings, err.Error())
+	}
+	for _, err := range f.fieldFormatter.Rejections() {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings
 }
 
 func (f *TextFormatter) Prettify(text string, airportService airports.Service) string {
+	if f.asciiMode == ASCIINames {
+		// Names are transliterated as they are looked up, leaving the rest of the text alone
+		airportService = asciiNameService{airportService}
+	}
+
+	// Work on LF-only text and write the breaks back in the input's style or the configured one
+	ending := f.lineEnding
+	if ending == "" {
+		ending = DetectLineEnding(text)
+	}
+	text = NormalizeLineEndings(text)
+	if f.structureProtector != nil {
+		// Code and block structure are hidden first so every later step, Unicode
+		// folding included, only sees prose. Protect keeps one line per line.
+		text = f.structureProtector.Protect(text)
+	}
+	// Look-alike spaces and dashes from PDFs and web pages would hide tokens from the regexes
+	text = f.whitespaceFormatter.NormalizeUnicode(text)
+
+	// Lint the tokens before they are rendered, so line numbers match the input
+	if f.chronologyChecker != nil {
+		f.lintWarnings = append(f.lintWarnings, f.chronologyChecker.CheckChronology(text, airportService)...)
+	}
+
 	// Apply transformations in correct order
 	text = f.whitespaceFormatter.ConvertControlChars(text)
 	text = f.whitespaceFormatter.CollapseBlankLines(text)
+	if f.typographyFormatter != nil {
+		// Typography goes before token replacement, which is how tokens and rendered values escape it
+		text = f.typographyFormatter.ApplyTypography(text)
+	}
+	if f.lineWrapper != nil {
+		// Marked now, while the tokens can still be told apart from the text around them
+		text = f.lineWrapper.GlueValues(text)
+	}
+	// Distance and airport-local time tokens contain airport codes, so they must run before code replacement
+	text = f.distanceFormatter.ReplaceDistances(text, airportService)
+	text = f.dateFormatter.ReplaceTimesThenDates(text, airportService)
+	// Field tokens hold a code followed by '.', which the code replacer would otherwise take
+	text = f.fieldFormatter.ReplaceFieldTokens(text, airportService)
 	text = f.airportFormatter.ReplaceAirportCodes(text, airportService)
-	text = f.dateFormatter.ReplaceTimesThenDates(text)
 	text = f.whitespaceFormatter.TrimExcessiveWhitespace(text)
-	return text
+	if f.structureProtector != nil {
+		text = f.structureProtector.Restore(text)
+	}
+	if f.asciiMode == ASCIIAll {
+		// Before wrapping, since spelling characters out changes line lengths
+		text = ToASCII(text)
+	}
+	if f.lineWrapper != nil {
+		text = f.lineWrapper.Wrap(text)
+	}
+	return ApplyLineEnding(text, ending)
 }
@@ -0,0 +1,302 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ISOTimestamp is a parsed ISO 8601 / RFC 3339 date or date-time
+type ISOTimestamp struct {
+	Time      time.Time
+	HasTime   bool // a time of day was given
+	HasOffset bool // a UTC offset or Z was given; Time is in UTC otherwise
+	Zulu      bool // the offset was written as Z or z rather than as a number
+}
+
+// ISOError explains why an input is not an accepted ISO 8601 value
+type ISOError struct {
+	Input  string
+	Reason string
+}
+
+func (e *ISOError) Error() string {
+	return fmt.Sprintf("%q: %s", e.Input, e.Reason)
+}
+
+// ParseISO8601 reads calendar dates (2025-03-05, 20250305), week dates
+// (2025-W10-3, 2025W103) and ordinal dates (2025-064, 2025064), optionally
+// followed by T and a time of day in extended (08:15:30.250) or basic
+// (081530,250) form and an offset of Z, z, ±hh, ±hhmm or ±hh:mm. The date and
+// time must both be basic or both extended; the offset may be written either
+// way. A space or lowercase t may stand in for T, as RFC 3339 allows.
+func ParseISO8601(input string) (ISOTimestamp, error) {
+	p := &isoScanner{text: strings.ReplaceAll(input, "−", "-")}
+	result, err := p.parse()
+	if err != nil {
+		return ISOTimestamp{}, &ISOError{Input: input, Reason: err.Error()}
+	}
+	return result, nil
+}
+
+type isoScanner struct {
+	text     string
+	pos      int
+	extended bool // the date was written with '-' separators
+	zulu     bool // the offset was written as Z or z
+}
+
+func (p *isoScanner) parse() (ISOTimestamp, error) {
+	year, month, day, err := p.date()
+	if err != nil {
+		return ISOTimestamp{}, err
+	}
+	if p.done() {
+		return ISOTimestamp{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}, nil
+	}
+
+	if sep := p.peek(); sep != 'T' && sep != 't' && sep != ' ' {
+		return ISOTimestamp{}, fmt.Errorf("expected 'T' between date and time, found %q", sep)
+	}
+	p.pos++
+
+	hour, minute, second, nanos, err := p.clock()
+	if err != nil {
+		return ISOTimestamp{}, err
+	}
+
+	location, hasOffset, err := p.offset()
+	if err != nil {
+		return ISOTimestamp{}, err
+	}
+	if !p.done() {
+		return ISOTimestamp{}, fmt.Errorf("unexpected %q after the time", p.text[p.pos:])
+	}
+
+	t := time.Date(year, month, day, hour, minute, second, nanos, location)
+	return ISOTimestamp{Time: t, HasTime: true, HasOffset: hasOffset, Zulu: p.zulu}, nil
+}
+
+// date reads the calendar, week or ordinal date at the start of the input
+func (p *isoScanner) date() (int, time.Month, int, error) {
+	year, err := p.number(4, "year")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	extended := p.accept('-')
+	p.extended = extended
+
+	if p.accept('W') {
+		week, err := p.number(2, "week")
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if extended && !p.accept('-') {
+			return 0, 0, 0, fmt.Errorf("expected '-' after the week number")
+		}
+		weekday, err := p.number(1, "weekday")
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return weekDate(year, week, weekday)
+	}
+
+	switch digits := p.digitRun(); {
+	case digits == 3:
+		ordinal, _ := p.number(3, "day of year")
+		return ordinalDate(year, ordinal)
+	case extended && digits == 2:
+		month, _ := p.number(2, "month")
+		if !p.accept('-') {
+			return 0, 0, 0, fmt.Errorf("expected '-' after the month")
+		}
+		day, err := p.number(2, "day")
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return calendarDate(year, month, day)
+	case !extended && digits == 4:
+		month, _ := p.number(2, "month")
+		day, _ := p.number(2, "day")
+		return calendarDate(year, month, day)
+	}
+	return 0, 0, 0, fmt.Errorf("expected month and day, week (Www) or day of year after the year")
+}
+
+// clock reads hh:mm[:ss[.fff]] or hhmm[ss[.fff]]
+func (p *isoScanner) clock() (int, int, int, int, error) {
+	hour, err := p.number(2, "hour")
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	extended := p.accept(':')
+	switch {
+	case p.extended && !extended:
+		return 0, 0, 0, 0, fmt.Errorf("the date is in extended form, so the time must be too (hh:mm)")
+	case !p.extended && extended:
+		return 0, 0, 0, 0, fmt.Errorf("the date is in basic form, so the time must be too (hhmm)")
+	}
+	minute, err := p.number(2, "minute")
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	second := 0
+	if (extended && p.accept(':')) || (!extended && p.digitRun() >= 2) {
+		if second, err = p.number(2, "second"); err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+
+	nanos := 0
+	if p.accept('.') || p.accept(',') {
+		digits := p.digitRun()
+		if digits == 0 {
+			return 0, 0, 0, 0, fmt.Errorf("expected digits after the decimal mark")
+		}
+		fraction := p.text[p.pos : p.pos+digits]
+		p.pos += digits
+		// Nanoseconds hold nine digits; anything finer is dropped
+		fraction = (fraction + "000000000")[:9]
+		fmt.Sscanf(fraction, "%d", &nanos)
+	}
+
+	switch {
+	case hour == 24 && (minute != 0 || second != 0 || nanos != 0):
+		return 0, 0, 0, 0, fmt.Errorf("hour 24 is only allowed as 24:00:00")
+	case hour > 24:
+		return 0, 0, 0, 0, fmt.Errorf("hour %d is out of range", hour)
+	case minute > 59:
+		return 0, 0, 0, 0, fmt.Errorf("minute %d is out of range", minute)
+	case second == 60:
+		return 0, 0, 0, 0, fmt.Errorf("leap seconds are not supported")
+	case second > 59:
+		return 0, 0, 0, 0, fmt.Errorf("second %d is out of range", second)
+	}
+	return hour, minute, second, nanos, nil
+}
+
+// offset reads an optional Z, ±hh, ±hhmm or ±hh:mm
+func (p *isoScanner) offset() (*time.Location, bool, error) {
+	if p.done() {
+		return time.UTC, false, nil
+	}
+	if p.accept('Z') || p.accept('z') {
+		p.zulu = true
+		return time.UTC, true, nil
+	}
+
+	sign := 1
+	switch {
+	case p.accept('+'):
+	case p.accept('-'):
+		sign = -1
+	default:
+		return nil, false, fmt.Errorf("expected a UTC offset, found %q", p.text[p.pos:])
+	}
+
+	hours, err := p.number(2, "offset hours")
+	if err != nil {
+		return nil, false, err
+	}
+	minutes := 0
+	if p.accept(':') || p.digitRun() > 0 {
+		if minutes, err = p.number(2, "offset minutes"); err != nil {
+			return nil, false, err
+		}
+	}
+	if hours > 23 {
+		return nil, false, fmt.Errorf("offset hours %d are out of range", hours)
+	}
+	if minutes > 59 {
+		return nil, false, fmt.Errorf("offset minutes %d are out of range", minutes)
+	}
+	return time.FixedZone("", sign*(hours*3600+minutes*60)), true, nil
+}
+
+func calendarDate(year, month, day int) (int, time.Month, int, error) {
+	if month < 1 || month > 12 {
+		return 0, 0, 0, fmt.Errorf("month %d is out of range", month)
+	}
+	if day < 1 || day > daysIn(year, time.Month(month)) {
+		return 0, 0, 0, fmt.Errorf("day %d is out of range for %04d-%02d", day, year, month)
+	}
+	return year, time.Month(month), day, nil
+}
+
+func ordinalDate(year, ordinal int) (int, time.Month, int, error) {
+	days := 365
+	if daysIn(year, time.February) == 29 {
+		days = 366
+	}
+	if ordinal < 1 || ordinal > days {
+		return 0, 0, 0, fmt.Errorf("day of year %d is out of range for %04d", ordinal, year)
+	}
+	t := time.Date(year, time.January, ordinal, 0, 0, 0, 0, time.UTC)
+	return t.Year(), t.Month(), t.Day(), nil
+}
+
+// weekDate converts an ISO week date; week 1 is the week holding 4 January
+func weekDate(year, week, weekday int) (int, time.Month, int, error) {
+	if weekday < 1 || weekday > 7 {
+		return 0, 0, 0, fmt.Errorf("weekday %d is out of range", weekday)
+	}
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	mondayOfWeek1 := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	if week < 1 || week > isoWeeksIn(year) {
+		return 0, 0, 0, fmt.Errorf("week %d is out of range for %04d", week, year)
+	}
+	t := mondayOfWeek1.AddDate(0, 0, (week-1)*7+weekday-1)
+	return t.Year(), t.Month(), t.Day(), nil
+}
+
+// isoWeeksIn returns 53 for years whose last ISO week is week 53
+func isoWeeksIn(year int) int {
+	_, week := time.Date(year, time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return week
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (p *isoScanner) done() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *isoScanner) peek() byte {
+	return p.text[p.pos]
+}
+
+func (p *isoScanner) accept(c byte) bool {
+	if !p.done() && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// digitRun counts the consecutive digits at the current position
+func (p *isoScanner) digitRun() int {
+	n := 0
+	for p.pos+n < len(p.text) && p.text[p.pos+n] >= '0' && p.text[p.pos+n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// number reads exactly width digits
+func (p *isoScanner) number(width int, what string) (int, error) {
+	if p.digitRun() < width {
+		if p.done() {
+			return 0, fmt.Errorf("missing %s", what)
+		}
+		return 0, fmt.Errorf("expected %d-digit %s at %q", width, what, p.text[p.pos:])
+	}
+	value := 0
+	for _, c := range p.text[p.pos : p.pos+width] {
+		value = value*10 + int(c-'0')
+	}
+	p.pos += width
+	return value, nil
+}
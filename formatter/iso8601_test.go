@@ -0,0 +1,96 @@
+package formatter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseISO8601Accepts(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      string // RFC 3339 with nanoseconds
+		hasTime   bool
+		hasOffset bool
+	}{
+		{"2025-03-05", "2025-03-05T00:00:00Z", false, false},
+		{"20250305", "2025-03-05T00:00:00Z", false, false},
+		{"2025-03-05T08:15", "2025-03-05T08:15:00Z", true, false},
+		{"2025-03-05T08:15:30Z", "2025-03-05T08:15:30Z", true, true},
+		{"2025-03-05t08:15:30z", "2025-03-05T08:15:30Z", true, true},
+		{"2025-03-05 08:15:30+01:00", "2025-03-05T08:15:30+01:00", true, true},
+		{"2025-03-05T08:15:30+0100", "2025-03-05T08:15:30+01:00", true, true},
+		{"2025-03-05T08:15:30+01", "2025-03-05T08:15:30+01:00", true, true},
+		{"2025-03-05T08:15:30−05:30", "2025-03-05T08:15:30-05:30", true, true},
+		{"2025-03-05T08:15:30.250-08:00", "2025-03-05T08:15:30.25-08:00", true, true},
+		{"2025-03-05T08:15:30,5Z", "2025-03-05T08:15:30.5Z", true, true},
+		{"2025-03-05T08:15:30.1234567891Z", "2025-03-05T08:15:30.123456789Z", true, true},
+		{"20250305T0815-0800", "2025-03-05T08:15:00-08:00", true, true},
+		{"20250305T081530,250Z", "2025-03-05T08:15:30.25Z", true, true},
+		{"20250305T081530+01:00", "2025-03-05T08:15:30+01:00", true, true},
+		{"2025-W10-3", "2025-03-05T00:00:00Z", false, false},
+		{"2025W103T0815Z", "2025-03-05T08:15:00Z", true, true},
+		{"2026-W01-1", "2025-12-29T00:00:00Z", false, false},
+		{"2020-W53-7", "2021-01-03T00:00:00Z", false, false},
+		{"2025-064", "2025-03-05T00:00:00Z", false, false},
+		{"2024-366", "2024-12-31T00:00:00Z", false, false},
+		{"2025-03-05T24:00Z", "2025-03-06T00:00:00Z", true, true},
+		{"2024-02-29", "2024-02-29T00:00:00Z", false, false},
+	}
+	for _, test := range tests {
+		got, err := ParseISO8601(test.input)
+		if err != nil {
+			t.Errorf("ParseISO8601(%q) error = %v", test.input, err)
+			continue
+		}
+		if s := got.Time.Format(time.RFC3339Nano); s != test.want || got.HasTime != test.hasTime || got.HasOffset != test.hasOffset {
+			t.Errorf("ParseISO8601(%q) = %s, time %v, offset %v; want %s, %v, %v",
+				test.input, s, got.HasTime, got.HasOffset, test.want, test.hasTime, test.hasOffset)
+		}
+	}
+}
+
+func TestParseISO8601Rejects(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"", "missing year"},
+		{"25-03-05", `expected 4-digit year at "25-03-05"`},
+		{"2025-13-05", "month 13 is out of range"},
+		{"2025-02-29", "day 29 is out of range for 2025-02"},
+		{"2025-0305", "expected month and day, week (Www) or day of year after the year"},
+		{"2025-03", "expected '-' after the month"},
+		{"2025-366", "day of year 366 is out of range for 2025"},
+		{"2025-000", "day of year 0 is out of range for 2025"},
+		{"2025-W53-1", "week 53 is out of range for 2025"},
+		{"2025-W00-1", "week 0 is out of range for 2025"},
+		{"2025-W10-8", "weekday 8 is out of range"},
+		{"2025-W103", "expected '-' after the week number"},
+		{"2025-03-05X08:15", `expected 'T' between date and time, found 'X'`},
+		{"2025-03-05T0815Z", "the date is in extended form, so the time must be too (hh:mm)"},
+		{"20250305T08:15Z", "the date is in basic form, so the time must be too (hhmm)"},
+		{"2025-W10-3T0815Z", "the date is in extended form, so the time must be too (hh:mm)"},
+		{"2025064T08:15Z", "the date is in basic form, so the time must be too (hhmm)"},
+		{"2025-03-05T24:01Z", "hour 24 is only allowed as 24:00:00"},
+		{"2025-03-05T24:00:00.5Z", "hour 24 is only allowed as 24:00:00"},
+		{"2025-03-05T25:00Z", "hour 25 is out of range"},
+		{"2025-03-05T08:60Z", "minute 60 is out of range"},
+		{"2025-03-05T23:59:60Z", "leap seconds are not supported"},
+		{"2025-03-05T08:15.Z", "expected digits after the decimal mark"},
+		{"2025-03-05T08:15+24:00", "offset hours 24 are out of range"},
+		{"2025-03-05T08:15+01:60", "offset minutes 60 are out of range"},
+		{"2025-03-05T08:15 UTC", `expected a UTC offset, found " UTC"`},
+		{"2025-03-05T08:15Z!", `unexpected "!" after the time`},
+	}
+	for _, test := range tests {
+		_, err := ParseISO8601(test.input)
+		isoErr, ok := err.(*ISOError)
+		if !ok {
+			t.Errorf("ParseISO8601(%q) error = %v, want %q", test.input, err, test.reason)
+			continue
+		}
+		if isoErr.Input != test.input || isoErr.Reason != test.reason {
+			t.Errorf("ParseISO8601(%q) = %q: %q, want %q", test.input, isoErr.Input, isoErr.Reason, test.reason)
+		}
+	}
+}
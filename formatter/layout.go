@@ -0,0 +1,164 @@
+package formatter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layout is a compiled output pattern for date and time tokens.
+//
+// A pattern is read left to right; the longest field name that matches is
+// replaced by its value and any other character is copied as is. Text in
+// square brackets is always copied literally, so write [at] rather than at.
+//
+//	YYYY  year             2025
+//	YY    two-digit year   25
+//	MMM   month name       Mar (from the locale)
+//	MM    month            03
+//	M     month, no pad    3
+//	DD    day              05
+//	D     day, no pad      5
+//	dddd  weekday          Wednesday (from the locale)
+//	ddd   short weekday    Wed
+//	HH    24-hour hour     08
+//	H     24-hour, no pad  8
+//	hh    12-hour hour     08
+//	h     12-hour, no pad  8
+//	mm    minutes          05
+//	ss    seconds          09
+//	A     AM/PM marker     PM (from the locale)
+//	a     lowercase marker pm
+//	Z     UTC offset       +01:00
+//	z     zone abbreviation, empty for bare offsets   BST
+type Layout struct {
+	parts []layoutPart
+}
+
+type layoutPart struct {
+	field   string
+	literal string
+}
+
+// layoutFields is ordered so that longer names are tried first
+var layoutFields = []string{
+	"dddd", "YYYY", "ddd", "MMM", "YY", "MM", "DD", "HH", "hh", "mm", "ss",
+	"M", "D", "H", "h", "A", "a", "Z", "z",
+}
+
+var errUnclosedLiteral = errors.New("unclosed [ in layout")
+
+// ParseLayout compiles a pattern; an empty pattern yields a nil Layout
+func ParseLayout(pattern string) (*Layout, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	layout := &Layout{}
+	for rest := pattern; rest != ""; {
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, errUnclosedLiteral
+			}
+			layout.parts = append(layout.parts, layoutPart{literal: rest[1:end]})
+			rest = rest[end+1:]
+			continue
+		}
+
+		field := ""
+		for _, name := range layoutFields {
+			if strings.HasPrefix(rest, name) {
+				field = name
+				break
+			}
+		}
+		if field != "" {
+			layout.parts = append(layout.parts, layoutPart{field: field})
+			rest = rest[len(field):]
+			continue
+		}
+
+		// Copy one whole rune so multi-byte literals survive
+		r := []rune(rest)[0]
+		layout.parts = append(layout.parts, layoutPart{literal: string(r)})
+		rest = rest[len(string(r)):]
+	}
+	return layout, nil
+}
+
+// Format renders t with the names and markers of locale
+func (l *Layout) Format(t time.Time, locale *Locale) string {
+	var out strings.Builder
+	for _, part := range l.parts {
+		if part.field == "" {
+			out.WriteString(part.literal)
+			continue
+		}
+		out.WriteString(formatField(part.field, t, locale))
+	}
+	return out.String()
+}
+
+func formatField(field string, t time.Time, locale *Locale) string {
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+
+	switch field {
+	case "YYYY":
+		return fmt.Sprintf("%04d", t.Year())
+	case "YY":
+		return fmt.Sprintf("%02d", t.Year()%100)
+	case "MMM":
+		return locale.Months[t.Month()-1]
+	case "MM":
+		return fmt.Sprintf("%02d", int(t.Month()))
+	case "M":
+		return fmt.Sprintf("%d", int(t.Month()))
+	case "DD":
+		return fmt.Sprintf("%02d", t.Day())
+	case "D":
+		return fmt.Sprintf("%d", t.Day())
+	case "dddd":
+		return locale.Weekday(t)
+	case "ddd":
+		return string([]rune(locale.Weekday(t))[:3])
+	case "HH":
+		return fmt.Sprintf("%02d", t.Hour())
+	case "H":
+		return fmt.Sprintf("%d", t.Hour())
+	case "hh":
+		return fmt.Sprintf("%02d", hour12)
+	case "h":
+		return fmt.Sprintf("%d", hour12)
+	case "mm":
+		return fmt.Sprintf("%02d", t.Minute())
+	case "ss":
+		return fmt.Sprintf("%02d", t.Second())
+	case "A":
+		return locale.Meridiem(t.Hour())
+	case "a":
+		return strings.ToLower(locale.Meridiem(t.Hour()))
+	case "Z":
+		return formatUTCOffset(t)
+	case "z":
+		if name, _ := t.Zone(); name != "" && !strings.HasPrefix(name, "+") && !strings.HasPrefix(name, "-") {
+			return name
+		}
+	}
+	return ""
+}
+
+// formatUTCOffset writes t's offset as +hh:mm
+func formatUTCOffset(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, offset/3600, offset%3600/60)
+}
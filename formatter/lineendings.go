@@ -0,0 +1,57 @@
+package formatter
+
+import "strings"
+
+// LineEnding is the byte sequence that ends each line of a document
+type LineEnding string
+
+const (
+	LF   LineEnding = "\n"
+	CRLF LineEnding = "\r\n"
+	CR   LineEnding = "\r"
+)
+
+// ParseLineEnding maps a setting such as "crlf" to its LineEnding. "auto" and
+// the empty string report false, meaning the input's own style is kept.
+func ParseLineEnding(name string) (LineEnding, bool) {
+	switch strings.ToLower(name) {
+	case "lf":
+		return LF, true
+	case "crlf":
+		return CRLF, true
+	case "cr":
+		return CR, true
+	}
+	return "", false
+}
+
+// DetectLineEnding returns the most common line ending in text: LF when LF is
+// tied for most common or text has a single line, CRLF when it ties with CR
+func DetectLineEnding(text string) LineEnding {
+	crlf := strings.Count(text, "\r\n")
+	cr := strings.Count(text, "\r") - crlf
+	lf := strings.Count(text, "\n") - crlf
+
+	switch {
+	case crlf > lf && crlf >= cr:
+		return CRLF
+	case cr > lf && cr > crlf:
+		return CR
+	}
+	return LF
+}
+
+// NormalizeLineEndings turns CRLF and lone CR breaks into LF, so a Windows
+// line break counts as one break rather than two
+func NormalizeLineEndings(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// ApplyLineEnding rewrites the LF breaks of normalized text as ending
+func ApplyLineEnding(text string, ending LineEnding) string {
+	if ending == LF {
+		return text
+	}
+	return strings.ReplaceAll(text, "\n", string(ending))
+}
@@ -0,0 +1,63 @@
+package formatter
+
+import (
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func TestDetectLineEnding(t *testing.T) {
+	tests := []struct {
+		input string
+		want  LineEnding
+	}{
+		{"", LF},
+		{"one line", LF},
+		{"a\nb\n", LF},
+		{"a\r\nb\r\n", CRLF},
+		{"a\rb\r", CR},
+		{"a\r\nb\r\nc\n", CRLF},
+		{"a\r\nb\nc\n", LF},
+		{"a\r\nb\n", LF}, // tied with LF
+		{"a\rb\rc\r\nd\n", CR},
+		{"a\rb\nc\n", LF},
+		{"a\r\n\r\nb\rc\n", CRLF},
+		{"a\r\nb\rc", CRLF}, // CRLF wins a tie with CR
+	}
+	for _, test := range tests {
+		if got := DetectLineEnding(test.input); got != test.want {
+			t.Errorf("DetectLineEnding(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeAndApplyLineEndings(t *testing.T) {
+	normalized := NormalizeLineEndings("a\r\nb\rc\n\r\nd")
+	if want := "a\nb\nc\n\nd"; normalized != want {
+		t.Fatalf("NormalizeLineEndings() = %q, want %q", normalized, want)
+	}
+	for ending, want := range map[LineEnding]string{
+		LF:   "a\nb\nc\n\nd",
+		CRLF: "a\r\nb\r\nc\r\n\r\nd",
+		CR:   "a\rb\rc\r\rd",
+	} {
+		if got := ApplyLineEnding(normalized, ending); got != want {
+			t.Errorf("ApplyLineEnding(%q) = %q, want %q", ending, got, want)
+		}
+	}
+}
+
+func TestPrettifyKeepsTheMostCommonLineEnding(t *testing.T) {
+	input := "#JFK\r\n#LHR\r\nx\ny"
+	tests := map[string]string{
+		"":     "John F Kennedy International Airport\r\nLondon Heathrow Airport\r\nx\r\ny",
+		"auto": "John F Kennedy International Airport\r\nLondon Heathrow Airport\r\nx\r\ny",
+		"lf":   "John F Kennedy International Airport\nLondon Heathrow Airport\nx\ny",
+		"cr":   "John F Kennedy International Airport\rLondon Heathrow Airport\rx\ry",
+	}
+	for lineEnding, want := range tests {
+		textFormatter := NewTextFormatter(types.FormatOptions{LineEnding: lineEnding})
+		if got := textFormatter.Prettify(input, newTestService()); got != want {
+			t.Errorf("line ending %q: Prettify(%q) = %q, want %q", lineEnding, input, got, want)
+		}
+	}
+}
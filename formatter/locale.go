@@ -0,0 +1,230 @@
+package formatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DateOrder is the order in which a locale writes day, month and year
+type DateOrder string
+
+const (
+	DayMonthYear DateOrder = "DMY"
+	MonthDayYear DateOrder = "MDY"
+	YearMonthDay DateOrder = "YMD"
+)
+
+// Locale holds the built-in names and conventions of one output language
+type Locale struct {
+	Code          string
+	Months        [12]string // abbreviated month names, January first
+	Weekdays      [7]string  // full weekday names, Sunday first
+	AM, PM        string
+	MeridiemFirst bool // write the AM/PM marker before the time, as in Japanese
+	DateOrder     DateOrder
+	DateSeparator string // placed between the date fields
+	DaySuffix     string // appended to the day, e.g. "." in German
+	YearSuffix    string
+	PadDay        bool // write 5 as "05"
+	Clock24       bool // clock used by plain T(...) tokens
+	Today         string
+	Tomorrow      string
+	Yesterday     string
+	InDays        string    // format for future days, e.g. "in %d days"
+	DaysAgo       string    // format for past days, e.g. "%d days ago"
+	Quotes        [4]string // opening and closing quotes, then the pair used inside them
+}
+
+// DefaultLocale reproduces the original English output
+const DefaultLocale = "en"
+
+var locales = map[string]*Locale{
+	"en": {
+		Code:          "en",
+		Months:        [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		Weekdays:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		AM:            "AM",
+		PM:            "PM",
+		DateOrder:     DayMonthYear,
+		DateSeparator: " ",
+		PadDay:        true,
+		Today:         "today",
+		Tomorrow:      "tomorrow",
+		Yesterday:     "yesterday",
+		InDays:        "in %d days",
+		DaysAgo:       "%d days ago",
+		Quotes:        [4]string{"“", "”", "‘", "’"},
+	},
+	"de": {
+		Code:          "de",
+		Months:        [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		Weekdays:      [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		AM:            "AM",
+		PM:            "PM",
+		DateOrder:     DayMonthYear,
+		DateSeparator: " ",
+		DaySuffix:     ".",
+		PadDay:        true,
+		Clock24:       true,
+		Today:         "heute",
+		Tomorrow:      "morgen",
+		Yesterday:     "gestern",
+		InDays:        "in %d Tagen",
+		DaysAgo:       "vor %d Tagen",
+		Quotes:        [4]string{"„", "“", "‚", "‘"},
+	},
+	"fr": {
+		Code:          "fr",
+		Months:        [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		Weekdays:      [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		AM:            "AM",
+		PM:            "PM",
+		DateOrder:     DayMonthYear,
+		DateSeparator: " ",
+		PadDay:        true,
+		Clock24:       true,
+		Today:         "aujourd'hui",
+		Tomorrow:      "demain",
+		Yesterday:     "hier",
+		InDays:        "dans %d jours",
+		DaysAgo:       "il y a %d jours",
+		Quotes:        [4]string{"«\u00A0", "\u00A0»", "“", "”"},
+	},
+	"es": {
+		Code:          "es",
+		Months:        [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		Weekdays:      [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		AM:            "a.m.",
+		PM:            "p.m.",
+		DateOrder:     DayMonthYear,
+		DateSeparator: " ",
+		PadDay:        true,
+		Clock24:       true,
+		Today:         "hoy",
+		Tomorrow:      "mañana",
+		Yesterday:     "ayer",
+		InDays:        "dentro de %d días",
+		DaysAgo:       "hace %d días",
+		Quotes:        [4]string{"«", "»", "“", "”"},
+	},
+	"et": {
+		Code:          "et",
+		Months:        [12]string{"jaan", "veebr", "märts", "apr", "mai", "juuni", "juuli", "aug", "sept", "okt", "nov", "dets"},
+		Weekdays:      [7]string{"pühapäev", "esmaspäev", "teisipäev", "kolmapäev", "neljapäev", "reede", "laupäev"},
+		AM:            "AM",
+		PM:            "PM",
+		DateOrder:     DayMonthYear,
+		DateSeparator: " ",
+		DaySuffix:     ".",
+		PadDay:        true,
+		Clock24:       true,
+		Today:         "täna",
+		Tomorrow:      "homme",
+		Yesterday:     "eile",
+		InDays:        "%d päeva pärast",
+		DaysAgo:       "%d päeva tagasi",
+		Quotes:        [4]string{"„", "“", "‚", "‘"},
+	},
+	"ja": {
+		Code:          "ja",
+		Months:        [12]string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		Weekdays:      [7]string{"日曜日", "月曜日", "火曜日", "水曜日", "木曜日", "金曜日", "土曜日"},
+		AM:            "午前",
+		PM:            "午後",
+		MeridiemFirst: true,
+		DateOrder:     YearMonthDay,
+		DaySuffix:     "日",
+		YearSuffix:    "年",
+		Clock24:       true,
+		Today:         "今日",
+		Tomorrow:      "明日",
+		Yesterday:     "昨日",
+		InDays:        "%d日後",
+		DaysAgo:       "%d日前",
+		Quotes:        [4]string{"「", "」", "『", "』"},
+	},
+}
+
+// LookupLocale returns the built-in locale for code, such as "de"
+func LookupLocale(code string) (*Locale, bool) {
+	locale, exists := locales[strings.ToLower(code)]
+	return locale, exists
+}
+
+// SupportedLocales lists the built-in locale codes in sorted order
+func SupportedLocales() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// FormatDate writes t's calendar date in the locale's field order
+func (l *Locale) FormatDate(t time.Time) string {
+	return joinDateParts(l.dateParts(t), l.DateSeparator)
+}
+
+// datePart is one rendered field of a date: 'D'ay, 'M'onth or 'Y'ear
+type datePart struct {
+	unit byte
+	text string
+}
+
+// dateParts renders t's day, month and year in the locale's field order
+func (l *Locale) dateParts(t time.Time) []datePart {
+	day := fmt.Sprintf("%d", t.Day())
+	if l.PadDay {
+		day = fmt.Sprintf("%02d", t.Day())
+	}
+	dayPart := datePart{'D', day + l.DaySuffix}
+	monthPart := datePart{'M', l.Months[t.Month()-1]}
+	yearPart := datePart{'Y', fmt.Sprintf("%d", t.Year()) + l.YearSuffix}
+
+	switch l.DateOrder {
+	case MonthDayYear:
+		return []datePart{monthPart, dayPart, yearPart}
+	case YearMonthDay:
+		return []datePart{yearPart, monthPart, dayPart}
+	}
+	return []datePart{dayPart, monthPart, yearPart}
+}
+
+func joinDateParts(parts []datePart, separator string) string {
+	texts := make([]string, len(parts))
+	for i, part := range parts {
+		texts[i] = part.text
+	}
+	return strings.Join(texts, separator)
+}
+
+// Weekday returns the locale's name for t's day of the week
+func (l *Locale) Weekday(t time.Time) string {
+	return l.Weekdays[t.Weekday()]
+}
+
+// RelativeDays describes a day offset from today in words
+func (l *Locale) RelativeDays(days int) string {
+	switch {
+	case days == 0:
+		return l.Today
+	case days == 1:
+		return l.Tomorrow
+	case days == -1:
+		return l.Yesterday
+	case days > 0:
+		return fmt.Sprintf(l.InDays, days)
+	}
+	return fmt.Sprintf(l.DaysAgo, -days)
+}
+
+// Meridiem returns the AM or PM marker for hour (0-23)
+func (l *Locale) Meridiem(hour int) string {
+	if hour >= 12 {
+		return l.PM
+	}
+	return l.AM
+}
@@ -0,0 +1,189 @@
+package formatter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StructureProtector hides parts of a document that token replacement and
+// whitespace trimming must not touch, and puts them back afterwards
+type StructureProtector interface {
+	Protect(text string) string
+	Restore(text string) string
+}
+
+// MarkdownProtector shields fenced and indented code blocks, inline code spans
+// and each line's block prefix (indentation, quote and list markers, heading
+// hashes).
+// Protected text is swapped for placeholders built from private-use runes,
+// which no token pattern matches and no whitespace rule collapses.
+type MarkdownProtector struct {
+	spans []string
+}
+
+func NewMarkdownProtector() *MarkdownProtector {
+	return &MarkdownProtector{}
+}
+
+const (
+	placeholderOpen  = ''
+	placeholderClose = ''
+)
+
+var (
+	fenceRe       = regexp.MustCompile("^[ \t]{0,3}(`{3,}|~{3,})")
+	headingRe     = regexp.MustCompile(`^[ \t]{0,3}#{1,6}(?:[ \t]|$)`)
+	listItemRe    = regexp.MustCompile(`^[ \t]*(?:>[ \t]?)*(?:[-*+]|\d{1,9}[.)])(?:[ \t]|$)`)
+	blockPrefixRe = regexp.MustCompile(`^(?:[ \t]*(?:>[ \t]?|[-*+][ \t]+|\d{1,9}[.)][ \t]+))*[ \t]*(?:#{1,6}(?:[ \t]+|$))?`)
+	placeholderRe = regexp.MustCompile("([0-9]+)")
+)
+
+// Protect replaces every protected part of text with a placeholder
+func (p *MarkdownProtector) Protect(text string) string {
+	p.spans = p.spans[:0]
+	lines := strings.Split(text, "\n")
+
+	code := codeLines(lines)
+	for i, line := range lines {
+		if code[i] {
+			// Code lines, blank ones inside a block included, are kept verbatim
+			lines[i] = p.hide(line)
+			continue
+		}
+
+		prefix := blockPrefixRe.FindString(line)
+		rest := p.hideCodeSpans(line[len(prefix):])
+		if prefix != "" {
+			rest = p.hide(prefix) + rest
+		}
+		lines[i] = rest
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeLines marks the lines that belong to a fenced code block, fence lines
+// included, or to an indented code block: lines indented four columns or
+// more that do not continue a paragraph or a list item. Blank lines between
+// two indented code lines belong to the block.
+func codeLines(lines []string) []bool {
+	code := make([]bool, len(lines))
+	fence := ""
+	prose := false  // the previous line is paragraph text, which an indented line continues
+	inList := false // indented lines after a list item continue the item
+	lastCode := -1  // the last indented code line, while only blank lines follow it
+	for i, line := range lines {
+		if fence != "" {
+			if marker := fenceRe.FindStringSubmatch(line); marker != nil && marker[1][0] == fence[0] && len(marker[1]) >= len(fence) && strings.TrimSpace(line[len(marker[0]):]) == "" {
+				fence = ""
+			}
+			code[i] = true
+			continue
+		}
+		if marker := fenceRe.FindStringSubmatch(line); marker != nil {
+			fence = marker[1]
+			code[i] = true
+			prose, lastCode = false, -1
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			prose = false
+			continue
+		}
+		indent := indentWidth(line)
+		if indent >= 4 && !prose && !inList {
+			for j := lastCode + 1; lastCode >= 0 && j < i; j++ {
+				code[j] = true
+			}
+			code[i] = true
+			lastCode = i
+			continue
+		}
+		lastCode = -1
+		if listItemRe.MatchString(line) {
+			inList = true
+		} else if indent == 0 && !prose {
+			inList = false
+		}
+		prose = !headingRe.MatchString(line)
+	}
+	return code
+}
+
+// indentWidth counts the columns of a line's leading spaces and tabs, with
+// tab stops every four columns
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4 - width%4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// Restore swaps the placeholders in text back for the original markdown
+func (p *MarkdownProtector) Restore(text string) string {
+	return placeholderRe.ReplaceAllStringFunc(text, func(match string) string {
+		i, err := strconv.Atoi(placeholderRe.FindStringSubmatch(match)[1])
+		if err != nil || i >= len(p.spans) {
+			return match
+		}
+		return p.spans[i]
+	})
+}
+
+// hideCodeSpans protects each inline code span: a run of backticks up to the
+// next run of the same length. An unmatched run is ordinary text.
+func (p *MarkdownProtector) hideCodeSpans(line string) string {
+	var out strings.Builder
+	for {
+		start := strings.IndexByte(line, '`')
+		if start < 0 {
+			break
+		}
+		ticks := backtickRun(line[start:])
+		end := -1
+		for i := start + ticks; i < len(line); {
+			if line[i] != '`' {
+				i++
+				continue
+			}
+			run := backtickRun(line[i:])
+			if run == ticks {
+				end = i + run
+				break
+			}
+			i += run
+		}
+		if end < 0 {
+			out.WriteString(line[:start+ticks])
+			line = line[start+ticks:]
+			continue
+		}
+		out.WriteString(line[:start])
+		out.WriteString(p.hide(line[start:end]))
+		line = line[end:]
+	}
+	out.WriteString(line)
+	return out.String()
+}
+
+func backtickRun(text string) int {
+	n := 0
+	for n < len(text) && text[n] == '`' {
+		n++
+	}
+	return n
+}
+
+func (p *MarkdownProtector) hide(span string) string {
+	p.spans = append(p.spans, span)
+	return string(placeholderOpen) + strconv.Itoa(len(p.spans)-1) + string(placeholderClose)
+}
@@ -0,0 +1,55 @@
+package formatter
+
+import (
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func TestMarkdownKeepsIndentedCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"after a blank line",
+			"Flights:\n\n    indented code #LHR\n\n\n\n    more  code #JFK\n\nBack to #LHR",
+			"Flights:\n\n    indented code #LHR\n\n\n\n    more  code #JFK\n\nBack to London Heathrow Airport",
+		},
+		{
+			"at the start of the document",
+			"\tcode #LHR\ntext #LHR",
+			"\tcode #LHR\ntext London Heathrow Airport",
+		},
+		{
+			"after a heading",
+			"# Notes\n    code #LHR",
+			"# Notes\n    code #LHR",
+		},
+		{
+			"continuing a paragraph",
+			"Meet at\n    #LHR",
+			"Meet at\n    London Heathrow Airport",
+		},
+		{
+			"continuing a list item",
+			"- Depart\n\n    from #LHR\n\nLater:\n\n    code #JFK",
+			"- Depart\n\n    from London Heathrow Airport\n\nLater:\n\n    code #JFK",
+		},
+	}
+	for _, test := range tests {
+		textFormatter := NewTextFormatter(types.FormatOptions{Markdown: true})
+		if got := textFormatter.Prettify(test.input, newTestService()); got != test.want {
+			t.Errorf("%s: Prettify(%q) = %q, want %q", test.name, test.input, got, test.want)
+		}
+	}
+}
+
+func TestWrapLeavesIndentedCodeBlocks(t *testing.T) {
+	input := "Intro text\n\n    a long line of code that must not be wrapped #LHR\n\nthe prose after it is wrapped"
+	want := "Intro text\n\n    a long line of code that must not be wrapped #LHR\n\nthe prose after\nit is wrapped"
+	textFormatter := NewTextFormatter(types.FormatOptions{Markdown: true, WrapWidth: 16})
+	if got := textFormatter.Prettify(input, newTestService()); got != want {
+		t.Errorf("Prettify(%q) = %q, want %q", input, got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package formatter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// dateRangeRe matches DR(start,end)
+	dateRangeRe = regexp.MustCompile(`\bDR\(([^,()]+),([^,()]+)\)`)
+	// dateTimeRe matches DT(timestamp)
+	dateTimeRe = regexp.MustCompile(`\bDT\(([^)]+)\)`)
+)
+
+// replaceDateRanges renders DR tokens as compact ranges that state the fields
+// both dates share only once: "05–07 Mar 2025", "28 Feb – 02 Mar 2025".
+func (f *DateTimeProcessor) replaceDateRanges(text string) string {
+	return dateRangeRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := dateRangeRe.FindStringSubmatch(match)
+		start, err := f.parseDate(strings.TrimSpace(parts[1]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+		end, err := f.parseDate(strings.TrimSpace(parts[2]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+		if end.Before(start) {
+			f.reject(match, errors.New("end is before start"))
+			return match
+		}
+		return f.formatDateRange(start, end)
+	})
+}
+
+func (f *DateTimeProcessor) formatDateRange(start, end time.Time) string {
+	// A custom layout has no fixed field order to collapse, so both ends are written in full
+	if f.dateLayout != nil {
+		return f.dateLayout.Format(start, f.locale) + " – " + f.dateLayout.Format(end, f.locale)
+	}
+
+	shared := map[byte]bool{}
+	if start.Year() == end.Year() {
+		shared['Y'] = true
+		if start.Month() == end.Month() {
+			shared['M'] = true
+			if start.Day() == end.Day() {
+				return f.locale.FormatDate(start)
+			}
+		}
+	}
+
+	// Shared fields are dropped from the tail of the start and the head of the
+	// end, so each appears once on the outer side of the dash
+	startParts := f.locale.dateParts(start)
+	for len(startParts) > 1 && shared[startParts[len(startParts)-1].unit] {
+		startParts = startParts[:len(startParts)-1]
+	}
+	endParts := f.locale.dateParts(end)
+	for len(endParts) > 1 && shared[endParts[0].unit] {
+		endParts = endParts[1:]
+	}
+
+	dash := " – "
+	if shared['M'] {
+		dash = "–"
+	}
+	separator := f.locale.DateSeparator
+	return joinDateParts(startParts, separator) + dash + joinDateParts(endParts, separator)
+}
+
+// replaceDateTimes renders DT tokens as date, time and offset together, on
+// the locale's usual clock
+func (f *DateTimeProcessor) replaceDateTimes(text string) string {
+	return dateTimeRe.ReplaceAllStringFunc(text, func(match string) string {
+		parts := dateTimeRe.FindStringSubmatch(match)
+		t, err := f.parseTime(strings.TrimSpace(parts[1]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+		if f.dateTimeLayout != nil {
+			return f.dateTimeLayout.Format(t, f.locale)
+		}
+
+		date := f.locale.FormatDate(t)
+		if f.dateLayout != nil {
+			date = f.dateLayout.Format(t, f.locale)
+		}
+		if f.locale.Clock24 {
+			return date + " " + f.format24HourTime(t, f.formatOffset(t))
+		}
+		return date + " " + f.format12HourTime(t, f.formatOffset(t))
+	})
+}
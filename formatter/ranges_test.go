@@ -0,0 +1,60 @@
+package formatter
+
+import (
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func TestDateRanges(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  string
+		want   string
+	}{
+		{"en", "DR(2025-03-05,2025-03-05)", "05 Mar 2025"},
+		{"en", "DR(2025-03-05,2025-03-07)", "05–07 Mar 2025"},
+		{"en", "DR(2025-02-28,2025-03-02)", "28 Feb – 02 Mar 2025"},
+		{"en", "DR(2024-12-30,2025-01-02)", "30 Dec 2024 – 02 Jan 2025"},
+		{"en", "DR(2024-03-05,2025-03-07)", "05 Mar 2024 – 07 Mar 2025"},
+		{"de", "DR(2025-03-05,2025-03-07)", "05.–07. Mär 2025"},
+		{"de", "DR(2025-02-28,2025-03-02)", "28. Feb – 02. Mär 2025"},
+		{"de", "DR(2024-12-30,2025-01-02)", "30. Dez 2024 – 02. Jan 2025"},
+		{"ja", "DR(2025-03-05,2025-03-05)", "2025年3月5日"},
+		{"ja", "DR(2025-03-05,2025-03-07)", "2025年3月5日–7日"},
+		{"ja", "DR(2025-02-28,2025-03-02)", "2025年2月28日 – 3月2日"},
+		{"ja", "DR(2024-12-30,2025-01-02)", "2024年12月30日 – 2025年1月2日"},
+		{"en", "DR( 2025-03-05 , 2025-03-07 )", "05–07 Mar 2025"},
+	}
+	for _, test := range tests {
+		dateFormatter := NewDateFormatter(types.FormatOptions{Locale: test.locale})
+		if got := dateFormatter.ReplaceTimesThenDates(test.input, newTestService()); got != test.want {
+			t.Errorf("%s: %s = %q, want %q", test.locale, test.input, got, test.want)
+		}
+	}
+}
+
+func TestDateRangesWithCustomLayout(t *testing.T) {
+	dateFormatter := NewDateFormatter(types.FormatOptions{DateLayout: "YYYY-MM-DD"})
+	if got, want := dateFormatter.ReplaceTimesThenDates("DR(2025-03-05,2025-03-07)", newTestService()), "2025-03-05 – 2025-03-07"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRejectedDateRanges(t *testing.T) {
+	tests := []struct {
+		input  string
+		reason string
+	}{
+		{"DR(2025-03-07,2025-03-05)", "DR(2025-03-07,2025-03-05) left unchanged: end is before start"},
+		{"DR(2025-03-05,2025-13-01)", "DR(2025-03-05,2025-13-01) left unchanged: month 13 is out of range"},
+	}
+	for _, test := range tests {
+		dateFormatter := NewDateFormatter(types.FormatOptions{})
+		if got := dateFormatter.ReplaceTimesThenDates(test.input, newTestService()); got != test.input {
+			t.Errorf("%s = %q, want it unchanged", test.input, got)
+		}
+		if rejections := dateFormatter.Rejections(); len(rejections) != 1 || rejections[0].Error() != test.reason {
+			t.Errorf("%s: Rejections() = %v, want [%s]", test.input, rejections, test.reason)
+		}
+	}
+}
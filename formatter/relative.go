@@ -0,0 +1,39 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// weekdayRe matches W(date)
+	weekdayRe = regexp.MustCompile(`\bW\(([^)]+)\)`)
+	// relativeDayRe matches REL(date)
+	relativeDayRe = regexp.MustCompile(`\bREL\(([^)]+)\)`)
+)
+
+// replaceWeekdays renders W tokens as the locale's weekday name
+func (f *DateTimeProcessor) replaceWeekdays(text string) string {
+	return weekdayRe.ReplaceAllStringFunc(text, func(match string) string {
+		t, err := f.parseDate(strings.TrimSpace(weekdayRe.FindStringSubmatch(match)[1]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+		return f.locale.Weekday(t)
+	})
+}
+
+// replaceRelativeDays renders REL tokens relative to the clock's current
+// date, e.g. "tomorrow" or "in 3 days". Both dates are taken as calendar
+// dates in their own zones.
+func (f *DateTimeProcessor) replaceRelativeDays(text string) string {
+	return relativeDayRe.ReplaceAllStringFunc(text, func(match string) string {
+		t, err := f.parseDate(strings.TrimSpace(relativeDayRe.FindStringSubmatch(match)[1]))
+		if err != nil {
+			f.reject(match, err)
+			return match
+		}
+		return f.locale.RelativeDays(calendarDaysBetween(f.clock(), t))
+	})
+}
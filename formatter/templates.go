@@ -0,0 +1,83 @@
+package formatter
+
+import (
+	"fmt"
+	"itinerary-prettifier/types"
+	"strings"
+)
+
+// AirportTemplate renders an airport record from a pattern such as
+// "{name} ({iata})". Text outside braces is copied as written.
+type AirportTemplate struct {
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string
+	field   string // empty for a literal
+}
+
+// Default templates reproduce the original output
+const (
+	DefaultIATATemplate = "{name}"
+	DefaultICAOTemplate = "{name}"
+	DefaultCityTemplate = "{municipality}"
+)
+
+// airportTemplateFields reads each template field from an airport record
+var airportTemplateFields = map[string]func(types.Airport) string{
+	"name":         func(a types.Airport) string { return a.Name },
+	"municipality": func(a types.Airport) string { return a.Municipality },
+	"city":         func(a types.Airport) string { return a.Municipality },
+	"country":      func(a types.Airport) string { return a.ISOCountry },
+	"iso_country":  func(a types.Airport) string { return a.ISOCountry },
+	"iata":         func(a types.Airport) string { return a.IATA },
+	"iata_code":    func(a types.Airport) string { return a.IATA },
+	"icao":         func(a types.Airport) string { return a.ICAO },
+	"icao_code":    func(a types.Airport) string { return a.ICAO },
+	"coordinates":  func(a types.Airport) string { return a.Coordinates },
+	"time_zone":    func(a types.Airport) string { return a.TimeZone },
+}
+
+// ParseAirportTemplate compiles a pattern; an empty pattern yields a nil template
+func ParseAirportTemplate(pattern string) (*AirportTemplate, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	template := &AirportTemplate{}
+	for rest := pattern; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			template.parts = append(template.parts, templatePart{literal: rest})
+			break
+		}
+		if start > 0 {
+			template.parts = append(template.parts, templatePart{literal: rest[:start]})
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed { in template %q", pattern)
+		}
+		field := strings.ToLower(strings.TrimSpace(rest[start+1 : start+end]))
+		if _, exists := airportTemplateFields[field]; !exists {
+			return nil, fmt.Errorf("unknown field {%s} in template %q", field, pattern)
+		}
+		template.parts = append(template.parts, templatePart{field: field})
+		rest = rest[start+end+1:]
+	}
+	return template, nil
+}
+
+// Render fills the template's fields from airport
+func (t *AirportTemplate) Render(airport types.Airport) string {
+	var out strings.Builder
+	for _, part := range t.parts {
+		if part.field == "" {
+			out.WriteString(part.literal)
+			continue
+		}
+		out.WriteString(airportTemplateFields[part.field](airport))
+	}
+	return out.String()
+}
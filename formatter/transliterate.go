@@ -0,0 +1,121 @@
+package formatter
+
+import (
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// asciiFallbacks spells out characters that have no ASCII base letter to
+// strip accents down to, and romanizes the Cyrillic and Greek alphabets
+var asciiFallbacks = map[rune]string{
+	'Æ': "AE", 'æ': "ae", 'Œ': "OE", 'œ': "oe", 'ß': "ss", 'ẞ': "SS",
+	'Ø': "O", 'ø': "o", 'Ł': "L", 'ł': "l", 'Đ': "D", 'đ': "d", 'Ð': "D", 'ð': "d",
+	'Þ': "TH", 'þ': "th", 'Ħ': "H", 'ħ': "h", 'ı': "i", 'Ŋ': "N", 'ŋ': "n",
+	'ĸ': "q", 'ŀ': "l", 'Ŀ': "L", 'ſ': "s", 'Ə': "E", 'ə': "e",
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'", '“': "\"", '”': "\"", '„': "\"", '″': "\"",
+	'«': "\"", '»': "\"", '‹': "'", '›': "'",
+	'–': "-", '—': "-", '―': "-", '…': "...", '•': "*", '·': ".",
+	'€': "EUR", '£': "GBP", '¥': "JPY", '©': "(c)", '®': "(R)", '™': "TM",
+	'×': "x", '÷': "/", '½': "1/2", '¼': "1/4", '¾': "3/4",
+	'¹': "1", '²': "2", '³': "3", 'ﬁ': "fi", 'ﬂ': "fl",
+	'→': "->", '←': "<-",
+	// Cyrillic, as used for Russian, Ukrainian, Belarusian and Serbian
+	'А': "A", 'а': "a", 'Б': "B", 'б': "b", 'В': "V", 'в': "v", 'Г': "G", 'г': "g", 'Д': "D",
+	'д': "d", 'Е': "E", 'е': "e", 'Ё': "Yo", 'ё': "yo", 'Ж': "Zh", 'ж': "zh", 'З': "Z",
+	'з': "z", 'И': "I", 'и': "i", 'Й': "Y", 'й': "y", 'К': "K", 'к': "k", 'Л': "L", 'л': "l",
+	'М': "M", 'м': "m", 'Н': "N", 'н': "n", 'О': "O", 'о': "o", 'П': "P", 'п': "p", 'Р': "R",
+	'р': "r", 'С': "S", 'с': "s", 'Т': "T", 'т': "t", 'У': "U", 'у': "u", 'Ф': "F", 'ф': "f",
+	'Х': "Kh", 'х': "kh", 'Ц': "Ts", 'ц': "ts", 'Ч': "Ch", 'ч': "ch", 'Ш': "Sh", 'ш': "sh",
+	'Щ': "Shch", 'щ': "shch", 'Ъ': "", 'ъ': "", 'Ы': "Y", 'ы': "y", 'Ь': "", 'ь': "",
+	'Э': "E", 'э': "e", 'Ю': "Yu", 'ю': "yu", 'Я': "Ya", 'я': "ya", 'Є': "Ye", 'є': "ye",
+	'І': "I", 'і': "i", 'Ї': "Yi", 'ї': "yi", 'Ґ': "G", 'ґ': "g", 'Ў': "U", 'ў': "u",
+	'Ј': "J", 'ј': "j", 'Љ': "Lj", 'љ': "lj", 'Њ': "Nj", 'њ': "nj", 'Ћ': "C", 'ћ': "c",
+	'Џ': "Dz", 'џ': "dz", 'Ђ': "Dj", 'ђ': "dj",
+	// Greek; letters with accents are reduced to these first
+	'Α': "A", 'α': "a", 'Β': "V", 'β': "v", 'Γ': "G", 'γ': "g", 'Δ': "D", 'δ': "d", 'Ε': "E",
+	'ε': "e", 'Ζ': "Z", 'ζ': "z", 'Η': "I", 'η': "i", 'Θ': "Th", 'θ': "th", 'Ι': "I",
+	'ι': "i", 'Κ': "K", 'κ': "k", 'Λ': "L", 'λ': "l", 'Μ': "M", 'μ': "m", 'Ν': "N", 'ν': "n",
+	'Ξ': "X", 'ξ': "x", 'Ο': "O", 'ο': "o", 'Π': "P", 'π': "p", 'Ρ': "R", 'ρ': "r", 'Σ': "S",
+	'σ': "s", 'Τ': "T", 'τ': "t", 'Υ': "Y", 'υ': "y", 'Φ': "F", 'φ': "f", 'Χ': "Ch",
+	'χ': "ch", 'Ψ': "Ps", 'ψ': "ps", 'Ω': "O", 'ω': "o", 'ς': "s",
+}
+
+// Transliterate returns an ASCII stand-in for r: accented letters lose their
+// marks, ligatures, typographic punctuation and Cyrillic and Greek letters
+// are spelled out, spaces become ' ', invisible characters are dropped and
+// anything else becomes "?"
+func Transliterate(r rune) string {
+	switch {
+	case r < utf8.RuneSelf:
+		return string(r)
+	case unicode.IsSpace(r):
+		return " "
+	case unicode.Is(unicode.Cf, r):
+		return ""
+	}
+	if fallback, ok := asciiFallbacks[r]; ok {
+		return fallback
+	}
+	// The canonical decomposition starts with the base letter
+	base, _ := utf8.DecodeRuneInString(norm.NFD.String(string(r)))
+	if base < utf8.RuneSelf {
+		return string(base)
+	}
+	if fallback, ok := asciiFallbacks[base]; ok {
+		return fallback
+	}
+	if norm.NFD.PropertiesString(string(r)).CCC() != 0 {
+		// A stray combining mark has nothing to stand in for
+		return ""
+	}
+	return "?"
+}
+
+// ToASCII transliterates every character of text that is not ASCII. The
+// marks the line wrapper puts around rendered values are kept.
+func ToASCII(text string) string {
+	if isASCII(text) {
+		return text
+	}
+	var out strings.Builder
+	for _, r := range text {
+		if r == glueOpen || r == glueClose {
+			out.WriteRune(r)
+			continue
+		}
+		out.WriteString(Transliterate(r))
+	}
+	return out.String()
+}
+
+// ASCII output modes
+const (
+	ASCIIOff   = "off"
+	ASCIINames = "names" // only looked-up airport names and municipalities
+	ASCIIAll   = "all"   // the whole document
+)
+
+// asciiNameService transliterates the names and municipalities it looks up
+type asciiNameService struct {
+	airports.Service
+}
+
+func (s asciiNameService) GetAirport(code string) (types.Airport, bool) {
+	airport, exists := s.Service.GetAirport(code)
+	airport.Name = ToASCII(airport.Name)
+	airport.Municipality = ToASCII(airport.Municipality)
+	return airport, exists
+}
+
+func (s asciiNameService) GetAirportName(code string) string {
+	return ToASCII(s.Service.GetAirportName(code))
+}
+
+func (s asciiNameService) GetCityName(code string) string {
+	return ToASCII(s.Service.GetCityName(code))
+}
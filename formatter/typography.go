@@ -0,0 +1,152 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TypographyFormatter swaps typewriter punctuation for typographic forms
+type TypographyFormatter interface {
+	ApplyTypography(text string) string
+}
+
+// SmartTypographer writes curly quotes in the locale's style, en and em
+// dashes for "--" and "---", an ellipsis for "...", and en dashes between
+// the two ends of a date or time range. Tokens are passed through untouched.
+type SmartTypographer struct {
+	locale *Locale
+}
+
+func NewTypographyFormatter(locale *Locale) *SmartTypographer {
+	return &SmartTypographer{locale: locale}
+}
+
+var (
+	// valueTokenRe matches the tokens that render a value: times, dates,
+	// durations and distances
+	valueTokenRe = regexp.MustCompile(`\b(?:DUR|LAY|DIST|DR|DT|T12|T24|T|D|W|REL)\([^()]*\)`)
+	// typographyTokenRe matches everything typography must pass through as
+	// written: value tokens, field tokens and airport codes
+	typographyTokenRe = regexp.MustCompile(valueTokenRe.String() + `|\{##?[A-Z0-9]{3,4}\.[A-Za-z0-9_]+\}|\*?##?[A-Z0-9]{3,4}`)
+	// rangeEndRe matches the tokens that can start or end a date or time range
+	rangeEndRe = regexp.MustCompile(`^(?:DR|DT|T12|T24|T|D|W)\(`)
+	// rangeGapRe matches the text between two tokens written as a range
+	rangeGapRe = regexp.MustCompile(`^( ?)-( ?)$`)
+	// clockRangeRe matches a range of plain clock times such as 08:15-10:30
+	clockRangeRe = regexp.MustCompile(`\b(\d{1,2}:\d{2})( ?)-( ?)(\d{1,2}:\d{2})\b`)
+	// ruleLineRe matches markdown rules and setext underlines
+	ruleLineRe = regexp.MustCompile(`^[ \t]*(?:-[ \t]*)+$`)
+)
+
+func (t *SmartTypographer) ApplyTypography(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if ruleLineRe.MatchString(line) {
+			continue
+		}
+		lines[i] = t.typesetLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// typesetLine rewrites the text between the tokens of one line
+func (t *SmartTypographer) typesetLine(line string) string {
+	tokens := typographyTokenRe.FindAllStringIndex(line, -1)
+	var out strings.Builder
+	previous := ' ' // the start of a line opens quotes, like a space
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		end := len(line)
+		if i < len(tokens) {
+			end = tokens[i][0]
+		}
+		gap := line[start:end]
+		if i > 0 && i < len(tokens) && rangeEndRe.MatchString(line[tokens[i-1][0]:]) && rangeEndRe.MatchString(line[end:]) {
+			gap = rangeGapRe.ReplaceAllString(gap, "$1–$2")
+		}
+		gap = clockRangeRe.ReplaceAllString(gap, "$1$2–$3$4")
+		out.WriteString(t.typeset(gap, previous))
+		if gap != "" {
+			previous, _ = utf8.DecodeLastRuneInString(gap)
+		}
+		if i < len(tokens) {
+			token := line[tokens[i][0]:tokens[i][1]]
+			out.WriteString(token)
+			previous, _ = utf8.DecodeLastRuneInString(token)
+			start = tokens[i][1]
+		}
+	}
+	return out.String()
+}
+
+// typeset replaces the quotes, dashes and dots of text, which follows previous
+func (t *SmartTypographer) typeset(text string, previous rune) string {
+	runes := []rune(text)
+	var out strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := runeAt(runes, i+1)
+		switch r {
+		case '"':
+			switch {
+			case unicode.IsDigit(previous):
+				out.WriteRune(r) // inches or seconds
+			case opensQuote(previous):
+				out.WriteString(t.locale.Quotes[0])
+			default:
+				out.WriteString(t.locale.Quotes[1])
+			}
+		case '\'':
+			switch {
+			case isWordRune(previous) && unicode.IsLetter(next):
+				out.WriteRune('’') // an apostrophe, as in "O'Hare"
+			case unicode.IsDigit(previous):
+				out.WriteRune(r) // feet or minutes
+			case opensQuote(previous):
+				out.WriteString(t.locale.Quotes[2])
+			default:
+				out.WriteString(t.locale.Quotes[3])
+			}
+		case '-', '.':
+			run := 1
+			for i+run < len(runes) && runes[i+run] == r {
+				run++
+			}
+			switch {
+			case r == '-' && run == 2 && previous != '!' && previous != '<' && runeAt(runes, i+run) != '>':
+				out.WriteRune('–')
+			case r == '-' && run == 3:
+				out.WriteRune('—')
+			case r == '.' && run == 3:
+				out.WriteRune('…')
+			default:
+				out.WriteString(string(runes[i : i+run]))
+			}
+			i += run - 1
+			r = runes[i]
+		default:
+			out.WriteRune(r)
+		}
+		previous = r
+	}
+	return out.String()
+}
+
+// opensQuote reports whether a quote after r starts a quotation
+func opensQuote(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("([{<–—-/“‘„‚«‹「『", r) || r == placeholderClose
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// runeAt returns runes[i], or 0 past the end
+func runeAt(runes []rune, i int) rune {
+	if i < len(runes) {
+		return runes[i]
+	}
+	return 0
+}
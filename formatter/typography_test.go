@@ -0,0 +1,42 @@
+package formatter
+
+import (
+	"itinerary-prettifier/airports"
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func newTestService() airports.Service {
+	heathrow := types.Airport{
+		Name: "London Heathrow Airport", Municipality: "London", ISOCountry: "GB", IATA: "LHR", ICAO: "EGLL",
+		Coordinates: "51.4706,-0.4619", Position: types.Position{Latitude: 51.4706, Longitude: -0.4619},
+	}
+	return airports.NewAirportService(airports.NewAirportRepository(map[string]types.Airport{
+		"#LHR":   heathrow,
+		"*#LHR":  heathrow,
+		"##EGLL": heathrow,
+		"#JFK": {
+			Name: "John F Kennedy International Airport", Municipality: "New York", ISOCountry: "US", IATA: "JFK", ICAO: "KJFK",
+			Coordinates: "40.6398,-73.7789", Position: types.Position{Latitude: 40.6398, Longitude: -73.7789},
+		},
+	}))
+}
+
+func TestTypographyLeavesAirportCodesResolvable(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  string
+		want   string
+	}{
+		{"en", `Meet at "#LHR" and #JFK's lounge`, "Meet at “London Heathrow Airport” and John F Kennedy International Airport’s lounge"},
+		{"en", `Then #LHR... and '*#LHR'`, "Then London Heathrow Airport… and ‘London’"},
+		{"en", `"##EGLL" -- {#LHR.iso_country}`, "“London Heathrow Airport” – GB"},
+		{"fr", `Rendez-vous à "#LHR" puis "*#LHR".`, "Rendez-vous à «\u00A0London Heathrow Airport\u00A0» puis «\u00A0London\u00A0»."},
+	}
+	for _, test := range tests {
+		textFormatter := NewTextFormatter(types.FormatOptions{SmartTypography: true, Locale: test.locale})
+		if got := textFormatter.Prettify(test.input, newTestService()); got != test.want {
+			t.Errorf("Prettify(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
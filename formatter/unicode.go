@@ -0,0 +1,29 @@
+package formatter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// lookalikeReplacer folds the look-alike characters common in text copied
+// from PDFs and web pages into their ASCII counterparts
+var lookalikeReplacer = strings.NewReplacer(
+	// Non-breaking, fixed-width, narrow and ideographic spaces
+	"\u00A0", " ", "\u2000", " ", "\u2001", " ", "\u2002", " ", "\u2003", " ",
+	"\u2004", " ", "\u2005", " ", "\u2006", " ", "\u2007", " ", "\u2008", " ",
+	"\u2009", " ", "\u200A", " ", "\u202F", " ", "\u205F", " ", "\u3000", " ",
+	// Zero-width spaces, word joiners, soft hyphens and byte order marks
+	"\u200B", "", "\u2060", "", "\uFEFF", "", "\u00AD", "", "\u180E", "",
+	// Hyphens and minus signs; en and em dashes are left as written
+	"\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2212", "-", "\uFE63", "-",
+	"\uFF0D", "-",
+)
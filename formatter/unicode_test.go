@@ -0,0 +1,28 @@
+package formatter
+
+import (
+	"itinerary-prettifier/types"
+	"testing"
+)
+
+func TestUnicodeFoldingLeavesMarkdownCodeAlone(t *testing.T) {
+	// Decomposed é and a non-breaking hyphen are folded in prose but kept in code
+	input := "Cafe\u0301 #LHR `e\u0301 #LHR`\n```\ne\u0301\u2011x\n```"
+	want := "Caf\u00e9 London Heathrow Airport `e\u0301 #LHR`\n```\ne\u0301\u2011x\n```"
+	textFormatter := NewTextFormatter(types.FormatOptions{Markdown: true})
+	if got := textFormatter.Prettify(input, newTestService()); got != want {
+		t.Errorf("Prettify(%q) = %q, want %q", input, got, want)
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := map[rune]string{
+		'\u00e9': "e", '\u00c5': "A", '\u212b': "A", '\u01fa': "A", '\u1e69': "s", '\u0386': "A",
+		'\u00df': "ss", '\u0416': "Zh", '\u0301': "", '\u00a0': " ", '\u200b': "", '\u6f22': "?",
+	}
+	for r, want := range tests {
+		if got := Transliterate(r); got != want {
+			t.Errorf("Transliterate(%q) = %q, want %q", r, got, want)
+		}
+	}
+}
@@ -3,22 +3,89 @@ package formatter
 import (
 	"regexp"
 	"strings"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // WhitespaceFormatter handles whitespace-related formatting
 type WhitespaceFormatter interface {
+	NormalizeUnicode(text string) string
 	ConvertControlChars(text string) string
 	CollapseBlankLines(text string) string
 	TrimExcessiveWhitespace(text string) string
 }
 
-type WhitespaceProcessor struct{}
+// WhitespaceProfile is a named set of whitespace rules
+type WhitespaceProfile struct {
+	Name            string
+	FoldUnicode     bool // NFC-normalize and fold look-alike spaces, hyphens and invisible characters
+	ConvertControls bool // turn \v, \f and \r into line breaks
+	MaxBlankLines   int  // consecutive blank lines kept; -1 keeps them all
+	TabWidth        int  // expand tabs to stops this far apart; 0 leaves tabs alone
+	CollapseSpaces  bool // squeeze runs of spaces and tabs inside a line to one space
+	TrimTrailing    bool // drop whitespace at the end of each line
+	KeepIndentation bool // leave leading whitespace as written
+}
+
+// DefaultWhitespaceProfile is the original, fixed behaviour
+const DefaultWhitespaceProfile = "aggressive"
+
+var whitespaceProfiles = map[string]WhitespaceProfile{
+	// preserve leaves the text exactly as written
+	"preserve": {
+		Name:            "preserve",
+		MaxBlankLines:   -1,
+		KeepIndentation: true,
+	},
+	// normalize keeps alignment and indentation but tidies line ends and blank runs
+	"normalize": {
+		Name:            "normalize",
+		FoldUnicode:     true,
+		ConvertControls: true,
+		MaxBlankLines:   1,
+		TabWidth:        4,
+		TrimTrailing:    true,
+		KeepIndentation: true,
+	},
+	// aggressive squeezes every run of whitespace
+	"aggressive": {
+		Name:            "aggressive",
+		FoldUnicode:     true,
+		ConvertControls: true,
+		MaxBlankLines:   1,
+		CollapseSpaces:  true,
+		TrimTrailing:    true,
+	},
+}
+
+// LookupWhitespaceProfile returns the profile called name
+func LookupWhitespaceProfile(name string) (WhitespaceProfile, bool) {
+	profile, exists := whitespaceProfiles[strings.ToLower(name)]
+	return profile, exists
+}
 
-func NewWhitespaceFormatter() *WhitespaceProcessor {
-	return &WhitespaceProcessor{}
+type WhitespaceProcessor struct {
+	profile WhitespaceProfile
+}
+
+func NewWhitespaceFormatter(profile WhitespaceProfile) *WhitespaceProcessor {
+	return &WhitespaceProcessor{profile: profile}
+}
+
+// NormalizeUnicode composes the text to NFC, turns non-breaking, narrow and
+// ideographic spaces into plain spaces, drops zero-width characters and byte
+// order marks, and writes hyphen and minus variants as '-'
+func (f *WhitespaceProcessor) NormalizeUnicode(text string) string {
+	if !f.profile.FoldUnicode {
+		return text
+	}
+	return lookalikeReplacer.Replace(norm.NFC.String(text))
 }
 
 func (f *WhitespaceProcessor) ConvertControlChars(text string) string {
+	if !f.profile.ConvertControls {
+		return text
+	}
 	var result strings.Builder
 	for _, r := range text {
 		switch r {
@@ -32,6 +99,9 @@ func (f *WhitespaceProcessor) ConvertControlChars(text string) string {
 }
 
 func (f *WhitespaceProcessor) CollapseBlankLines(text string) string {
+	if f.profile.MaxBlankLines < 0 {
+		return text
+	}
 	lines := strings.Split(text, "\n")
 	result := make([]string, 0, len(lines))
 
@@ -39,7 +109,7 @@ func (f *WhitespaceProcessor) CollapseBlankLines(text string) string {
 	for _, line := range lines {
 		if strings.TrimSpace(line) == "" {
 			blankCount++
-			if blankCount <= 1 {
+			if blankCount <= f.profile.MaxBlankLines {
 				result = append(result, "")
 			}
 		} else {
@@ -51,12 +121,47 @@ func (f *WhitespaceProcessor) CollapseBlankLines(text string) string {
 	return strings.Join(result, "\n")
 }
 
+var spaceRunRe = regexp.MustCompile(`[ \t]+`)
+
 func (f *WhitespaceProcessor) TrimExcessiveWhitespace(text string) string {
 	lines := strings.Split(text, "\n")
 	for i, line := range lines {
-		re := regexp.MustCompile(`[ \t]+`)
-		lines[i] = re.ReplaceAllString(line, " ")
-		lines[i] = strings.TrimSpace(lines[i])
+		if f.profile.TabWidth > 0 {
+			line = expandTabs(line, f.profile.TabWidth)
+		}
+
+		body := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(body)]
+		if f.profile.CollapseSpaces {
+			body = spaceRunRe.ReplaceAllString(body, " ")
+		}
+		if f.profile.TrimTrailing {
+			body = strings.TrimRight(body, " \t")
+		}
+		if !f.profile.KeepIndentation || (body == "" && f.profile.TrimTrailing) {
+			indent = ""
+		}
+		lines[i] = indent + body
 	}
 	return strings.Join(lines, "\n")
 }
+
+// expandTabs replaces each tab with spaces up to the next tab stop
+func expandTabs(line string, width int) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var out strings.Builder
+	column := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := width - column%width
+			out.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			continue
+		}
+		out.WriteRune(r)
+		column++
+	}
+	return out.String()
+}
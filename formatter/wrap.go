@@ -0,0 +1,162 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LineWrapper breaks lines that run past a column limit
+type LineWrapper interface {
+	// GlueValues marks the value tokens of text so that what they render to
+	// is never broken across lines
+	GlueValues(text string) string
+	// Wrap breaks long lines and removes the marks left by GlueValues
+	Wrap(text string) string
+}
+
+// WordWrapper breaks each line longer than width at the spaces between
+// words. Continuation lines repeat the line's indentation and quote markers
+// and are indented past its list marker. Lines are never joined, so each
+// itinerary entry keeps its own line.
+type WordWrapper struct {
+	width    int
+	markdown bool // leave code blocks, headings and tables unwrapped
+}
+
+func NewLineWrapper(width int, markdown bool) *WordWrapper {
+	return &WordWrapper{width: width, markdown: markdown}
+}
+
+// Private-use runes around a rendered value; the spaces between them do not break
+const (
+	glueOpen  = '\uE002'
+	glueClose = '\uE003'
+)
+
+var (
+	// wrapPrefixRe splits a line's indentation and quote markers from its list marker
+	wrapPrefixRe = regexp.MustCompile(`^([ \t]*(?:>[ \t]?)*)((?:[-*+•]|\d{1,9}[.)])[ \t]+)?`)
+	// unwrappedLineRe matches markdown lines that must stay on one line
+	unwrappedLineRe = regexp.MustCompile(`^[ \t]{0,3}(?:#|\|)`)
+)
+
+func (w *WordWrapper) GlueValues(text string) string {
+	return valueTokenRe.ReplaceAllString(text, string(glueOpen)+"$0"+string(glueClose))
+}
+
+func (w *WordWrapper) Wrap(text string) string {
+	lines := strings.Split(text, "\n")
+	wrapped := make([]string, 0, len(lines))
+	var code []bool
+	if w.markdown {
+		code = codeLines(lines)
+	}
+	for i, line := range lines {
+		if w.markdown && (code[i] || unwrappedLineRe.MatchString(line)) {
+			wrapped = append(wrapped, unglue(line))
+			continue
+		}
+		wrapped = append(wrapped, w.wrapLine(line)...)
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+// wrapLine fills words onto lines of at most width columns; a word wider than
+// that gets a line of its own
+func (w *WordWrapper) wrapLine(line string) []string {
+	if displayWidth(unglue(line)) <= w.width {
+		return []string{unglue(line)}
+	}
+	prefix := wrapPrefixRe.FindStringSubmatch(line)
+	first := prefix[0]
+	continuation := prefix[1] + strings.Repeat(" ", displayWidth(prefix[2]))
+
+	var lines []string
+	current := first
+	empty := true
+	for _, word := range splitWords(line[len(prefix[0]):]) {
+		text := unglue(word.text)
+		if !empty && displayWidth(current)+displayWidth(word.space)+displayWidth(text) > w.width {
+			lines = append(lines, current)
+			current, empty = continuation, true
+		}
+		if !empty {
+			current += word.space
+		}
+		current += text
+		empty = false
+	}
+	return append(lines, current)
+}
+
+type wrapWord struct {
+	space string // the spacing written before the word
+	text  string
+}
+
+// splitWords cuts text at runs of spaces and tabs outside glued values
+func splitWords(text string) []wrapWord {
+	var words []wrapWord
+	var space, word strings.Builder
+	depth := 0
+	for _, r := range text {
+		switch {
+		case r == glueOpen:
+			depth++
+		case r == glueClose && depth > 0:
+			depth--
+		case (r == ' ' || r == '\t') && depth == 0:
+			if word.Len() > 0 {
+				words = append(words, wrapWord{space: space.String(), text: word.String()})
+				space.Reset()
+				word.Reset()
+			}
+			space.WriteRune(r)
+			continue
+		}
+		word.WriteRune(r)
+	}
+	if word.Len() > 0 {
+		words = append(words, wrapWord{space: space.String(), text: word.String()})
+	}
+	return words
+}
+
+func unglue(text string) string {
+	if !strings.ContainsAny(text, string(glueOpen)+string(glueClose)) {
+		return text
+	}
+	return strings.NewReplacer(string(glueOpen), "", string(glueClose), "").Replace(text)
+}
+
+// displayWidth counts the columns text takes on a terminal or printer, with
+// East Asian wide characters taking two and combining marks none
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case isWide(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+func isWide(r rune) bool {
+	if r < 0x1100 || r == utf8.RuneError {
+		return false
+	}
+	return r <= 0x115F ||
+		(r >= 0x2E80 && r <= 0xA4CF && r != 0x303F) ||
+		(r >= 0xAC00 && r <= 0xD7A3) ||
+		(r >= 0xF900 && r <= 0xFAFF) ||
+		(r >= 0xFE30 && r <= 0xFE4F) ||
+		(r >= 0xFF00 && r <= 0xFF60) ||
+		(r >= 0xFFE0 && r <= 0xFFE6) ||
+		(r >= 0x20000 && r <= 0x3FFFD)
+}
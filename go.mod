@@ -1,3 +1,5 @@
 module itinerary-prettifier
 
 go 1.24.5
+
+require golang.org/x/text v0.34.0
@@ -7,6 +7,8 @@ import (
 	"itinerary-prettifier/config"
 	"itinerary-prettifier/fileio"
 	"itinerary-prettifier/formatter"
+	"itinerary-prettifier/types"
+	"os"
 	"strings"
 )
 
@@ -19,7 +21,6 @@ func main() {
 	fileChecker := fileio.NewFileChecker()
 	csvParser := airports.NewCSVParser()
 	airportLoader := airports.NewAirportLoader(csvParser)
-	textFormatter := formatter.NewTextFormatter()
 
 	// Parse command line arguments
 	config, err := cliParser.Parse()
@@ -36,6 +37,25 @@ func main() {
 		return
 	}
 
+	// Apply the duplicate-code policy; the validator already vetted the value
+	duplicatePolicy, _ := airports.ParseDuplicatePolicy(config.OnDuplicate)
+	csvParser.SetDuplicatePolicy(duplicatePolicy)
+
+	// Apply the file encodings; characters the output encoding lacks are transliterated
+	if encoding, exists := fileio.ParseEncoding(config.InputEncoding); exists {
+		fileReader.SetEncoding(encoding)
+	}
+	if encoding, exists := fileio.ParseEncoding(config.OutputEncoding); exists {
+		fileWriter.SetEncoding(encoding, formatter.Transliterate)
+	}
+
+	if config.Command == types.CommandValidateLookup {
+		os.Exit(validateLookups(airports.NewLookupValidator(csvParser), config.LookupPaths))
+	}
+	if config.Command == types.CommandBuildIndex {
+		os.Exit(buildIndexes(airports.NewBinaryIndexer(csvParser), csvParser, config.LookupPaths))
+	}
+
 	// Check if output file exists (to prevent overwrite on error)
 	if fileChecker.Exists(config.OutputPath) {
 		// We'll proceed but ensure we don't overwrite on error
@@ -49,7 +69,13 @@ func main() {
 	}
 
 	// Load airport data
-	airportRepo, err := airportLoader.Load(config.LookupPath)
+	airportRepo, err := airportLoader.LoadLayered(config.LookupPaths)
+
+	// Report rows that collided while loading, including the one that aborted it
+	for _, conflict := range airportLoader.Conflicts() {
+		fmt.Fprintln(os.Stderr, "warning:", conflict)
+	}
+
 	if err != nil {
 		// Check what type of error it is
 		if strings.Contains(err.Error(), "airport lookup not found") {
@@ -63,14 +89,77 @@ func main() {
 	}
 
 	// Create airport service
-	airportService := airports.NewAirportService(airportRepo)
+	var airportService airports.Service = airports.NewAirportService(airportRepo)
+	recorder := airports.NewRecordingService(airportService, airportRepo)
+	if config.ListSources {
+		airportService = recorder
+	}
 
 	// Process and format text
+	textFormatter := formatter.NewTextFormatter(config.Format)
 	output := textFormatter.Prettify(input, airportService)
+	for _, warning := range textFormatter.Warnings() {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
 
 	// Write output file
 	if err := fileWriter.WriteFile(config.OutputPath, output); err != nil {
 		fmt.Println("Failed to write output")
 		return
 	}
+
+	// Report where each resolved code was looked up
+	if config.ListSources {
+		printSources(recorder.Resolved(), airportRepo)
+	}
+}
+
+func printSources(codes []string, repo airports.Repository) {
+	sourceRepo, ok := repo.(airports.SourceRepository)
+	if !ok {
+		return
+	}
+	for _, code := range codes {
+		source, _ := sourceRepo.SourceOf(code)
+		fmt.Printf("%s\t%s\n", code, source)
+	}
+}
+
+// validateLookups prints a quality report per file and returns the exit status
+func validateLookups(validator airports.DatasetValidator, paths []string) int {
+	status := 0
+	for _, path := range paths {
+		report, err := validator.Validate(path)
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		for _, issue := range report.Issues {
+			fmt.Printf("%s: %s\n", path, issue)
+		}
+		fmt.Printf("%s: %d rows checked, %d issues\n", path, report.Rows, len(report.Issues))
+		if !report.OK() {
+			status = 1
+		}
+	}
+	return status
+}
+
+// buildIndexes writes a binary index for each lookup file and returns the exit status
+func buildIndexes(indexer airports.Indexer, parser *airports.CSVParser, paths []string) int {
+	reported := 0
+	for _, path := range paths {
+		indexPath, err := indexer.Build(path)
+		for _, conflict := range parser.Conflicts()[reported:] {
+			fmt.Fprintln(os.Stderr, "warning:", conflict)
+		}
+		reported = len(parser.Conflicts())
+		if err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			return 1
+		}
+		fmt.Printf("%s: wrote %s\n", path, indexPath)
+	}
+	return 0
 }
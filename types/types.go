@@ -1,5 +1,12 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Airport holds CSV airport data
 type Airport struct {
 	Name         string
@@ -7,14 +14,55 @@ type Airport struct {
 	Municipality string
 	ICAO         string
 	IATA         string
-	Coordinates  string
+	Coordinates  string            // "lat,lon" as written in the lookup file
+	Position     Position          // Coordinates parsed and range-checked
+	TimeZone     string            // IANA zone name such as "Europe/London"; empty when unknown
+	Extra        map[string]string // other CSV columns by lower-case header; nil when there are none
 }
 
+// Commands selectable as the first command line argument
+const (
+	CommandPrettify       = ""
+	CommandValidateLookup = "validate-lookup"
+	CommandBuildIndex     = "build-index"
+)
+
 // Config holds application configuration
 type Config struct {
-	InputPath  string
-	OutputPath string
-	LookupPath string
+	Command        string
+	InputPath      string
+	OutputPath     string
+	LookupPaths    []string // lowest precedence first
+	ListSources    bool
+	OnDuplicate    string // first, last or error
+	InputEncoding  string // auto, utf-8, utf-16le, utf-16be, windows-1252 or iso-8859-1
+	OutputEncoding string // utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252, iso-8859-1 or ascii
+	Format         FormatOptions
+}
+
+// FormatOptions holds the settings that shape the prettified output
+type FormatOptions struct {
+	DistanceUnit      string        // km, mi or nm
+	MinLayover        time.Duration // LAY tokens below this are flagged
+	DayMarkers        bool          // append "+1" to arrivals on a later day than the departure
+	Locale            string        // en, de, fr, es, et or ja
+	DateLayout        string        // output pattern for D tokens; see formatter.Layout
+	Time12Layout      string        // output pattern for T12 tokens
+	Time24Layout      string        // output pattern for T24 tokens
+	DateTimeLayout    string        // output pattern for DT tokens
+	CheckChronology   bool          // warn about out-of-order timestamps, DST gaps and overlaps, and wrong offsets
+	Markdown          bool          // keep code, indentation and block markers of markdown input intact
+	WhitespaceProfile string        // preserve, normalize or aggressive
+	LineEnding        string        // auto, lf, crlf or cr
+	SmartTypography   bool          // curly quotes, dashes and ellipses in the surrounding text
+	WrapWidth         int           // break lines longer than this many columns; 0 leaves them
+	ASCII             string        // off, names (airport names only) or all
+	IATATemplate      string        // how #ABC codes are written, e.g. "{name} ({iata})"; see formatter.AirportTemplate
+	ICAOTemplate      string        // how ##ABCD codes are written
+	CityTemplate      string        // how *#ABC and *##ABCD codes are written
+	// Clock supplies "now" for relative tokens; nil means the system clock.
+	// Fix it to make output reproducible.
+	Clock func() time.Time
 }
 
 // ProcessingResult holds the result of processing
@@ -22,3 +70,32 @@ type ProcessingResult struct {
 	Output string
 	Error  error
 }
+
+// Position is a latitude/longitude pair in decimal degrees
+type Position struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// ParsePosition reads a "lat,lon" pair and checks both ranges
+func ParsePosition(value string) (Position, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return Position{}, fmt.Errorf("%q is not a lat,lon pair", value)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return Position{}, fmt.Errorf("latitude %q is not a number", parts[0])
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return Position{}, fmt.Errorf("longitude %q is not a number", parts[1])
+	}
+	if lat < -90 || lat > 90 {
+		return Position{}, fmt.Errorf("latitude %g is outside -90..90", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return Position{}, fmt.Errorf("longitude %g is outside -180..180", lon)
+	}
+	return Position{Latitude: lat, Longitude: lon}, nil
+}